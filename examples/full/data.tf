@@ -13,3 +13,7 @@ data "netactuate_bgp_sessions" "bgp_sessions" {
 
   depends_on = [netactuate_bgp_sessions.bgp_sessions]
 }
+
+data "netactuate_plans" "plan" {
+  name = netactuate_server.server.plan
+}
@@ -5,8 +5,7 @@ import (
 	"flag"
 	"log"
 
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/plugin"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5/tf5server"
 	"github.com/netactuate/terraform-provider-netactuate/netactuate"
 )
 
@@ -16,19 +15,22 @@ func main() {
 	flag.BoolVar(&debugMode, "debug", false, "set to true to run the provider with support for debuggers like delve")
 	flag.Parse()
 
-	opts := &plugin.ServeOpts{
-		ProviderFunc: func() *schema.Provider {
-			return netactuate.Provider()
-		},
+	ctx := context.Background()
+
+	// The SDKv2 and framework providers are served together while resources
+	// move to the framework.
+	serverFactory, err := netactuate.ProviderServer(ctx)
+	if err != nil {
+		log.Fatal(err.Error())
 	}
 
+	var serveOpts []tf5server.ServeOpt
 	if debugMode {
-		err := plugin.Debug(context.Background(), "github.com/netactuate/netactuate", opts)
-		if err != nil {
-			log.Fatal(err.Error())
-		}
-		return
+		serveOpts = append(serveOpts, tf5server.WithManagedDebug())
 	}
 
-	plugin.Serve(opts)
+	err = tf5server.Serve("github.com/netactuate/netactuate", serverFactory, serveOpts...)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
 }
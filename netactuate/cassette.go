@@ -0,0 +1,241 @@
+package netactuate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const (
+	cassetteModeEnv = "NETACTUATE_CASSETTE_MODE"
+	cassettePathEnv = "NETACTUATE_CASSETTE"
+
+	cassetteRecord = "record"
+	cassetteReplay = "replay"
+
+	scrubbedAPIKey   = "REDACTED"
+	scrubbedPassword = "REDACTED"
+)
+
+// apiKeyRequired reports whether the provider needs an API key. Replays never
+// reach the API and recordings don't contain the key, so replays don't.
+func apiKeyRequired() bool {
+	return os.Getenv(cassetteModeEnv) != cassetteReplay
+}
+
+// cassette is a recording of API interactions, stored as JSON.
+type cassette struct {
+	Interactions []interaction `json:"interactions"`
+}
+
+type interaction struct {
+	Method       string `json:"method"`
+	Path         string `json:"path"`
+	Query        string `json:"query,omitempty"`
+	RequestBody  string `json:"request_body,omitempty"`
+	StatusCode   int    `json:"status_code"`
+	ResponseBody string `json:"response_body"`
+}
+
+func (i interaction) key() string {
+	return i.Method + " " + i.Path + "?" + i.Query + " " + i.RequestBody
+}
+
+// cassetteTransport records API interactions to a cassette file, or replays
+// them from one without touching the network. Interactions are matched on
+// method, path, query and scrubbed body, ignoring the host so that a cassette
+// recorded against the live API replays against any api_url. Identical
+// requests, such as the status polls in wait4Status, are replayed in the
+// order recorded and the last answer is repeated once they run out.
+type cassetteTransport struct {
+	mode string
+	path string
+	next http.RoundTripper
+
+	mu       sync.Mutex
+	cassette cassette
+	played   map[string]int
+}
+
+var (
+	cassettesMu sync.Mutex
+	cassettes   = make(map[string]*cassetteTransport)
+)
+
+// cassetteTransportFor returns the transport for a cassette, reusing the one
+// from an earlier provider configuration in the same process so that
+// recordings accumulate and replays carry on where they left off.
+func cassetteTransportFor(mode, path string, next http.RoundTripper) (*cassetteTransport, error) {
+	cassettesMu.Lock()
+	defer cassettesMu.Unlock()
+
+	if t, ok := cassettes[path]; ok && t.mode == mode {
+		// Both providers configure on every run, and the second may pass
+		// the cassette back in; don't chain the transport to itself.
+		if next != t {
+			t.mu.Lock()
+			t.next = next
+			t.mu.Unlock()
+		}
+		return t, nil
+	}
+
+	t, err := newCassetteTransport(mode, path, next)
+	if err != nil {
+		return nil, err
+	}
+	cassettes[path] = t
+	return t, nil
+}
+
+func newCassetteTransport(mode, path string, next http.RoundTripper) (*cassetteTransport, error) {
+	if path == "" {
+		return nil, fmt.Errorf("%s must be set when %s is %q", cassettePathEnv, cassetteModeEnv, mode)
+	}
+	t := &cassetteTransport{
+		mode:   mode,
+		path:   path,
+		next:   next,
+		played: make(map[string]int),
+	}
+
+	switch mode {
+	case cassetteRecord:
+		return t, nil
+	case cassetteReplay:
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &t.cassette); err != nil {
+			return nil, fmt.Errorf("could not parse cassette %s: %w", path, err)
+		}
+		return t, nil
+	}
+
+	return nil, fmt.Errorf("%s must be %q or %q, got %q", cassetteModeEnv, cassetteRecord, cassetteReplay, mode)
+}
+
+func (t *cassetteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	query := req.URL.Query()
+	if query.Has("key") {
+		query.Set("key", scrubbedAPIKey)
+	}
+
+	i := interaction{
+		Method:      req.Method,
+		Path:        req.URL.Path,
+		Query:       query.Encode(),
+		RequestBody: scrubBody(req, body),
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.mode == cassetteReplay {
+		return t.replay(req, i)
+	}
+	return t.record(req, i)
+}
+
+// scrubBody replaces the root password in form bodies, such as server builds,
+// so that it isn't written to the cassette. Replays match on the scrubbed
+// body, so they don't need the password used for recording.
+func scrubBody(req *http.Request, body []byte) string {
+	if req.Header.Get("Content-Type") != "application/x-www-form-urlencoded" {
+		return string(body)
+	}
+	form, err := url.ParseQuery(string(body))
+	if err != nil || !form.Has("password") {
+		return string(body)
+	}
+	form.Set("password", scrubbedPassword)
+	return form.Encode()
+}
+
+func (t *cassetteTransport) replay(req *http.Request, i interaction) (*http.Response, error) {
+	var matches []interaction
+	for _, recorded := range t.cassette.Interactions {
+		if recorded.key() == i.key() {
+			matches = append(matches, recorded)
+		}
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no recorded interaction for %s %s in %s", i.Method, (&url.URL{Path: i.Path, RawQuery: i.Query}).String(), t.path)
+	}
+
+	n := t.played[i.key()]
+	t.played[i.key()] = n + 1
+	recorded := matches[min(n, len(matches)-1)]
+
+	return &http.Response{
+		Status:        http.StatusText(recorded.StatusCode),
+		StatusCode:    recorded.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader([]byte(recorded.ResponseBody))),
+		ContentLength: int64(len(recorded.ResponseBody)),
+		Request:       req,
+	}, nil
+}
+
+func (t *cassetteTransport) record(req *http.Request, i interaction) (*http.Response, error) {
+	next := t.next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	resp, err := next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	i.StatusCode = resp.StatusCode
+	i.ResponseBody = string(body)
+	t.cassette.Interactions = append(t.cassette.Interactions, i)
+
+	// Save after every interaction so that a failed run still leaves a
+	// usable cassette behind.
+	if err := t.save(); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func (t *cassetteTransport) save() error {
+	data, err := json.MarshalIndent(t.cassette, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(t.path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(t.path, data, 0o644)
+}
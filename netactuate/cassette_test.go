@@ -0,0 +1,246 @@
+package netactuate
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/netactuate/gona/gona"
+)
+
+const testAPIKey = "s3cr3t-api-key"
+
+// configureCassette configures the provider the way Terraform would, with the
+// cassette mode taken from the environment.
+func configureCassette(t *testing.T, mode, path, apiURL string) apiClient {
+	t.Helper()
+	t.Setenv(cassetteModeEnv, mode)
+	t.Setenv(cassettePathEnv, path)
+
+	p := Provider()
+	diags := p.Configure(context.Background(), terraform.NewResourceConfigRaw(map[string]interface{}{
+		"api_key": testAPIKey,
+		"api_url": apiURL,
+	}))
+	if diags.HasError() {
+		t.Fatalf("configure: %v", diags)
+	}
+	return p.Meta().(apiClient)
+}
+
+func TestCassetteRecordReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	m := newMockAPI()
+	id := m.addServer("web1.example.com", 1)
+
+	c := configureCassette(t, cassetteRecord, path, m.URL())
+	key, err := c.CreateSSHKey("default_key", testSSHKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorded, err := c.GetServer(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), testAPIKey) {
+		t.Fatal("cassette contains the API key")
+	}
+	if !strings.Contains(string(data), scrubbedAPIKey) {
+		t.Fatal("cassette doesn't contain the scrubbed API key")
+	}
+
+	// Replay with the mock API gone and a different host and key.
+	c = configureCassette(t, cassetteReplay, path, "http://127.0.0.1:1/api/")
+	replayedKey, err := c.CreateSSHKey("default_key", testSSHKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replayedKey != key {
+		t.Errorf("replayed SSH key %+v, recorded %+v", replayedKey, key)
+	}
+	replayed, err := c.GetServer(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replayed != recorded {
+		t.Errorf("replayed server %+v, recorded %+v", replayed, recorded)
+	}
+
+	if _, err := c.GetLocations(); err == nil || !strings.Contains(err.Error(), "no recorded interaction") {
+		t.Fatalf("expected a missing interaction error, got %v", err)
+	}
+}
+
+func TestCassetteScrubsPassword(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	m := newMockAPI()
+	defer m.Close()
+
+	build := func(password string) *gona.CreateServerRequest {
+		return &gona.CreateServerRequest{
+			Plan:     "VR1x1x25",
+			Location: 1,
+			Image:    10,
+			FQDN:     "web1.example.com",
+			Password: password,
+		}
+	}
+
+	c := configureCassette(t, cassetteRecord, path, m.URL())
+	recorded, err := c.CreateServer(build("hunter2-recorded"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "hunter2") {
+		t.Fatal("cassette contains the root password")
+	}
+	if !strings.Contains(string(data), "password="+scrubbedPassword) {
+		t.Fatal("cassette doesn't contain the scrubbed password")
+	}
+
+	// Replay matches on the scrubbed body, whatever the password.
+	c = configureCassette(t, cassetteReplay, path, m.URL())
+	replayed, err := c.CreateServer(build("hunter2-replayed"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replayed != recorded {
+		t.Errorf("replayed build %+v, recorded %+v", replayed, recorded)
+	}
+}
+
+func TestCassetteReplayOrder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	m := newMockAPI()
+	defer m.Close()
+	id := m.addServer("web1.example.com", 1)
+
+	c := configureCassette(t, cassetteRecord, path, m.URL())
+	if err := c.DeleteServer(id, false); err != nil {
+		t.Fatal(err)
+	}
+	var statuses []string
+	for i := 0; i < m.transitionPolls; i++ {
+		server, err := c.GetServer(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		statuses = append(statuses, server.ServerStatus)
+	}
+
+	c = configureCassette(t, cassetteReplay, path, m.URL())
+	if err := c.DeleteServer(id, false); err != nil {
+		t.Fatal(err)
+	}
+	// Repeated polls replay in order, then repeat the last answer.
+	for i, want := range append(statuses, statuses[len(statuses)-1]) {
+		server, err := c.GetServer(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if server.ServerStatus != want {
+			t.Errorf("poll %d: got %q, want %q", i, server.ServerStatus, want)
+		}
+	}
+}
+
+func TestCassetteInvalidMode(t *testing.T) {
+	if _, err := newCassetteTransport("rewind", "cassette.json", nil); err == nil {
+		t.Fatal("expected an error for an unknown mode")
+	}
+	if _, err := newCassetteTransport(cassetteRecord, "", nil); err == nil {
+		t.Fatal("expected an error without a cassette path")
+	}
+}
+
+func TestCassetteReplayWithoutAPIKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	m := newMockAPI()
+	c := configureCassette(t, cassetteRecord, path, m.URL())
+	if _, err := c.GetLocations(); err != nil {
+		t.Fatal(err)
+	}
+	m.Close()
+
+	t.Setenv("NETACTUATE_API_KEY", "")
+	t.Setenv("NETACTUATE_API_URL", "http://127.0.0.1:1/api/")
+	t.Setenv(cassetteModeEnv, cassetteReplay)
+
+	// Both providers configure through the mux server.
+	ctx := context.Background()
+	serverFactory, err := ProviderServer(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := serverFactory()
+	schemaResp, err := server.GetProviderSchema(ctx, &tfprotov5.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	configType := schemaResp.Provider.ValueType()
+	config, err := tfprotov5.NewDynamicValue(configType, tftypes.NewValue(configType, map[string]tftypes.Value{
+		"api_key": tftypes.NewValue(tftypes.String, nil),
+		"api_url": tftypes.NewValue(tftypes.String, nil),
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := server.ConfigureProvider(ctx, &tfprotov5.ConfigureProviderRequest{Config: &config})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range resp.Diagnostics {
+		t.Errorf("%s: %s", d.Summary, d.Detail)
+	}
+
+	p := Provider()
+	if diags := p.Configure(ctx, terraform.NewResourceConfigRaw(nil)); diags.HasError() {
+		t.Fatalf("configure: %v", diags)
+	}
+	if _, err := p.Meta().(apiClient).GetLocations(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Recording still needs the key.
+	t.Setenv(cassetteModeEnv, cassetteRecord)
+	if diags := Provider().Configure(ctx, terraform.NewResourceConfigRaw(nil)); !diags.HasError() {
+		t.Fatal("expected recording without an API key to fail")
+	}
+}
+
+func TestCassetteTransportForItself(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	first, err := cassetteTransportFor(cassetteRecord, path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Configuring again while the cassette is installed passes it back in.
+	second, err := cassetteTransportFor(cassetteRecord, path, first)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if second != first {
+		t.Fatal("expected the cassette transport to be reused")
+	}
+	if second.next == second {
+		t.Fatal("expected the cassette transport not to wrap itself")
+	}
+}
@@ -0,0 +1,29 @@
+package netactuate
+
+import "github.com/netactuate/gona/gona"
+
+// apiClient is the part of *gona.Client used by the provider. Resources take
+// the provider meta as an apiClient so that tests can substitute a fake.
+type apiClient interface {
+	GetServers() ([]gona.Server, error)
+	GetServer(id int) (gona.Server, error)
+	CreateServer(r *gona.CreateServerRequest) (gona.ServerBuild, error)
+	BuildServer(id int, r *gona.BuildServerRequest) (gona.ServerBuild, error)
+	DeleteServer(id int, cancelBilling bool) error
+	UnlinkServer(id int) error
+
+	GetLocations() ([]gona.Location, error)
+	GetOSs() ([]gona.OS, error)
+	GetPlans() ([]gona.Plan, error)
+	GetIPs(mbPkgID int) (gona.IPs, error)
+
+	GetSSHKeys() ([]gona.SSHKey, error)
+	GetSSHKey(id int) (gona.SSHKey, error)
+	CreateSSHKey(name, key string) (gona.SSHKey, error)
+	DeleteSSHKey(id int) error
+
+	GetBGPSessions(mbPkgID int) ([]*gona.BGPSession, error)
+	CreateBGPSessions(mbPkgID int, groupID int, isIPV6 bool, redundant bool) (*gona.BGPSession, error)
+}
+
+var _ apiClient = (*gona.Client)(nil)
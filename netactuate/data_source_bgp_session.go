@@ -7,7 +7,6 @@ import (
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	"github.com/netactuate/gona/gona"
 )
 
 func dataSourceBGPSessions() *schema.Resource {
@@ -95,7 +94,7 @@ func dataSourceBGPSessions() *schema.Resource {
 }
 
 func dataSourceBGPSessionsRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*gona.Client)
+	c := m.(apiClient)
 
 	MbPkgID := d.Get("mbpkgid").(int)
 
@@ -0,0 +1,172 @@
+package netactuate
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/netactuate/gona/gona"
+)
+
+// planNameRegex matches plan names such as "VR1x1x25" (vCPU x RAM GB x disk GB).
+var planNameRegex = regexp.MustCompile(`^[A-Za-z]+(\d+)x(\d+)x(\d+)$`)
+
+func dataSourcePlans() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourcePlansRead,
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return the plan with this name",
+			},
+			"plans": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"vcpu": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"ram": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"disk": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"bandwidth": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"price": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"available": {
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "Whether the plan is in stock",
+						},
+					},
+				},
+			},
+			"enabled_locations": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Short names of the locations that accept new servers. The API doesn't report stock per location",
+			},
+		},
+	}
+}
+
+func dataSourcePlansRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(apiClient)
+
+	plans, err := c.GetPlans()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	locations, err := c.GetLocations()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	name := d.Get("name").(string)
+
+	result := make([]map[string]interface{}, 0, len(plans))
+
+	for _, plan := range plans {
+		if name != "" && !strings.EqualFold(plan.Name, name) {
+			continue
+		}
+
+		p := make(map[string]interface{})
+
+		p["id"] = plan.ID
+		p["name"] = plan.Name
+		p["vcpu"] = planVCPU(plan.Name)
+		p["ram"] = plan.RAM
+		p["disk"] = plan.Disk
+		p["bandwidth"] = plan.Transfer
+		p["price"] = plan.Price
+		p["available"] = planAvailable(plan)
+
+		result = append(result, p)
+	}
+
+	if name != "" && len(result) == 0 {
+		return diag.Errorf("Provided plan %q doesn't exist", name)
+	}
+
+	err = d.Set("plans", result)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	err = d.Set("enabled_locations", enabledLocations(locations))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if name != "" {
+		d.SetId(name)
+	} else {
+		d.SetId("plans")
+	}
+
+	return nil
+}
+
+// planVCPU extracts the vCPU count from the plan name, returning 0 for names
+// that don't follow the "VR<cpu>x<ram>x<disk>" convention.
+func planVCPU(name string) int {
+	cpu, _, _, _ := planSpec(name)
+	return cpu
+}
+
+// planSpec extracts the vCPU count, RAM in GB and disk in GB from a plan name
+// such as "VR1x1x25". ok is false for names that don't follow the convention.
+func planSpec(name string) (cpu, ram, disk int, ok bool) {
+	match := planNameRegex.FindStringSubmatch(name)
+	if match == nil {
+		return 0, 0, 0, false
+	}
+	cpu, _ = strconv.Atoi(match[1])
+	ram, _ = strconv.Atoi(match[2])
+	disk, _ = strconv.Atoi(match[3])
+	return cpu, ram, disk, true
+}
+
+// enabledLocations returns the short names of all locations that currently
+// accept new builds.
+func enabledLocations(locations []gona.Location) []string {
+	var result []string
+	for _, location := range locations {
+		if location.Disabled != 0 || locationCode(location.Name) == "" {
+			continue
+		}
+		result = append(result, locationCode(location.Name))
+	}
+	return result
+}
+
+// planAvailable reports whether the API lists a plan as in stock.
+func planAvailable(plan gona.Plan) bool {
+	return plan.Available != "" && plan.Available != "0"
+}
@@ -0,0 +1,69 @@
+package netactuate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestPlanVCPU(t *testing.T) {
+	tests := map[string]int{
+		"VR1x1x25":  1,
+		"VR16x64x1": 16,
+		"custom":    0,
+		"":          0,
+	}
+
+	for name, want := range tests {
+		if got := planVCPU(name); got != want {
+			t.Errorf("planVCPU(%q): got %d, want %d", name, got, want)
+		}
+	}
+}
+
+func TestPlanSpec(t *testing.T) {
+	cpu, ram, disk, ok := planSpec("VR16x64x1")
+	if !ok || cpu != 16 || ram != 64 || disk != 1 {
+		t.Errorf("planSpec(%q): got %d, %d, %d, %v", "VR16x64x1", cpu, ram, disk, ok)
+	}
+	if _, _, _, ok := planSpec("custom"); ok {
+		t.Errorf("planSpec(%q): expected no match", "custom")
+	}
+}
+
+func TestDataSourcePlansRead(t *testing.T) {
+	m := newMockAPI()
+	defer m.Close()
+
+	c := testClient(m)
+
+	d := schema.TestResourceDataRaw(t, dataSourcePlans().Schema, map[string]interface{}{"name": "vr2x2x50"})
+	if diags := dataSourcePlansRead(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if got := d.Get("plans.0.vcpu").(int); got != 2 {
+		t.Errorf("vcpu: got %d, want 2", got)
+	}
+	if got := d.Get("plans.0.available").(bool); !got {
+		t.Errorf("available: got %v, want true", got)
+	}
+	// LHR is disabled in the mock API
+	if got := d.Get("enabled_locations").([]interface{}); len(got) != 2 {
+		t.Errorf("enabled_locations: got %v", got)
+	}
+
+	// VR4x4x100 is out of stock in the mock API
+	d = schema.TestResourceDataRaw(t, dataSourcePlans().Schema, map[string]interface{}{"name": "VR4x4x100"})
+	if diags := dataSourcePlansRead(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if got := d.Get("plans.0.available").(bool); got {
+		t.Errorf("available: got %v, want false", got)
+	}
+
+	d = schema.TestResourceDataRaw(t, dataSourcePlans().Schema, map[string]interface{}{"name": "VR9x9x9"})
+	if diags := dataSourcePlansRead(context.Background(), d, c); !diags.HasError() {
+		t.Fatal("expected an error for an unknown plan")
+	}
+}
@@ -2,7 +2,10 @@ package netactuate
 
 import (
 	"context"
+	"fmt"
+	"net"
 	"strconv"
+	"strings"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -10,140 +13,233 @@ import (
 )
 
 func dataSourceServer() *schema.Resource {
+	s := dataSourceServerSchema()
+	s["id"] = &schema.Schema{
+		Type:         schema.TypeInt,
+		Optional:     true,
+		Computed:     true,
+		ExactlyOneOf: []string{"id", "hostname"},
+	}
+	s["hostname"] = &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		Computed:     true,
+		ExactlyOneOf: []string{"id", "hostname"},
+		Description:  "Look the server up by hostname instead of id",
+	}
+	s["location"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		Description: "Location used to disambiguate servers with the same hostname",
+	}
+
 	return &schema.Resource{
 		ReadContext: dataSourceServerRead,
-		Schema: map[string]*schema.Schema{
-			"id": {
-				Type:     schema.TypeInt,
-				Required: true,
-			},
-			"hostname": {
-				Type:     schema.TypeString,
-				Computed: true,
-			},
-			"plan_id": {
-				Type:     schema.TypeInt,
-				Computed: true,
-			},
-			"package": {
-				Type:     schema.TypeString,
-				Computed: true,
-			},
-			"location_id": {
-				Type:     schema.TypeInt,
-				Computed: true,
-			},
-			"image": {
-				Type:     schema.TypeString,
-				Computed: true,
-			},
-			"image_id": {
-				Type:     schema.TypeInt,
-				Computed: true,
-			},
-			"ip_v4": {
-				Type:     schema.TypeString,
-				Computed: true,
-			},
-			"ip_v6": {
-				Type:     schema.TypeString,
-				Computed: true,
-			},
-			"public_ipv4": {
-				Type:     schema.TypeString,
-				Computed: true,
-			},
-			"public_ipv6": {
-				Type:     schema.TypeString,
-				Computed: true,
-			},
-			"bgp_peers": {
-				Type:     schema.TypeList,
-				Computed: true,
-				Elem: &schema.Resource{
-					Schema: map[string]*schema.Schema{
-						"group_id": {
-							Type:     schema.TypeInt,
-							Computed: true,
-						},
-						"localasn": {
-							Type:     schema.TypeInt,
-							Computed: true,
-						},
-						"peerasn": {
-							Type:     schema.TypeInt,
-							Computed: true,
-						},
-						"localpeerv4": {
-							Type:     schema.TypeString,
-							Computed: true,
-						},
-						"localpeerv6": {
-							Type:     schema.TypeString,
-							Computed: true,
-						},
-						"ipv4": {
-							Type:     schema.TypeList,
-							Computed: true,
-							Elem:     schema.TypeString,
-						},
-						"ipv6": {
-							Type:     schema.TypeList,
-							Computed: true,
-							Elem:     schema.TypeString,
-						},
+		Schema:      s,
+	}
+}
+
+// dataSourceServerSchema returns the computed server attributes shared by the
+// netactuate_server and netactuate_servers data sources.
+func dataSourceServerSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"hostname": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"plan_id": {
+			Type:     schema.TypeInt,
+			Computed: true,
+		},
+		"package": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"location_id": {
+			Type:     schema.TypeInt,
+			Computed: true,
+		},
+		"image": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"image_id": {
+			Type:     schema.TypeInt,
+			Computed: true,
+		},
+		"ip_v4": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"ip_v6": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"public_ipv4": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"public_ipv6": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"ipv4_addresses": ipAddressesSchema(),
+		"ipv6_addresses": ipAddressesSchema(),
+		"bgp_peers": {
+			Type:     schema.TypeList,
+			Computed: true,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"group_id": {
+						Type:     schema.TypeInt,
+						Computed: true,
+					},
+					"localasn": {
+						Type:     schema.TypeInt,
+						Computed: true,
+					},
+					"peerasn": {
+						Type:     schema.TypeInt,
+						Computed: true,
+					},
+					"localpeerv4": {
+						Type:     schema.TypeString,
+						Computed: true,
+					},
+					"localpeerv6": {
+						Type:     schema.TypeString,
+						Computed: true,
+					},
+					"ipv4": {
+						Type:     schema.TypeList,
+						Computed: true,
+						Elem:     schema.TypeString,
+					},
+					"ipv6": {
+						Type:     schema.TypeList,
+						Computed: true,
+						Elem:     schema.TypeString,
 					},
 				},
 			},
-			"state": {
-				Type:     schema.TypeString,
-				Computed: true,
-			},
-			"status": {
-				Type:     schema.TypeString,
-				Computed: true,
-			},
+		},
+		"state": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"status": {
+			Type:     schema.TypeString,
+			Computed: true,
 		},
 	}
 }
 
 func dataSourceServerRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*gona.Client)
+	c := m.(apiClient)
 
-	server, err := c.GetServer(d.Get("id").(int))
+	var server gona.Server
+	var err error
+
+	if id, ok := d.GetOk("id"); ok {
+		server, err = c.GetServer(id.(int))
+	} else {
+		var hostname string
+		hostname, err = hostnameToASCII(d.Get("hostname").(string))
+		if err == nil {
+			server, err = getServerByHostname(hostname, d.Get("location").(string), c)
+		}
+	}
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	ips, err := c.GetIPs(server.ID)
+	values, err := flattenServer(server, c, true)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	bgpSessions, err := c.GetBGPSessions(server.ID)
+	var diags diag.Diagnostics
+
+	for key, value := range values {
+		setValue(key, value, d, &diags)
+	}
+
+	if diags == nil {
+		d.SetId(strconv.Itoa(server.ID))
+	}
+
+	return diags
+}
+
+// getServerByHostname returns the only server with the given hostname,
+// optionally restricted to a location.
+func getServerByHostname(hostname string, location string, c apiClient) (gona.Server, error) {
+	servers, err := c.GetServers()
 	if err != nil {
-		return diag.FromErr(err)
+		return gona.Server{}, err
 	}
 
-	var diags diag.Diagnostics
+	var matches []gona.Server
+	for _, server := range servers {
+		if strings.EqualFold(server.Name, hostname) && matchServerLocation(server, location) {
+			matches = append(matches, server)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return gona.Server{}, fmt.Errorf("No server found with hostname %q", hostname)
+	case 1:
+		return matches[0], nil
+	}
 
-	setValue("hostname", server.Name, d, &diags)
-	setValue("package", server.Package, d, &diags)
-	setValue("plan_id", server.PlanID, d, &diags)
-	setValue("location_id", server.LocationID, d, &diags)
-	setValue("image", server.OS, d, &diags)
-	setValue("image_id", server.OSID, d, &diags)
-	setValue("ip_v4", server.PrimaryIPv4, d, &diags)
-	setValue("ip_v6", server.PrimaryIPv6, d, &diags)
-	setValue("status", server.ServerStatus, d, &diags)
-	setValue("state", server.PowerStatus, d, &diags)
+	ids := make([]string, len(matches))
+	for i, server := range matches {
+		ids[i] = strconv.Itoa(server.ID)
+	}
+	return gona.Server{}, fmt.Errorf("Found %d servers with hostname %q (%s), set location or use id instead",
+		len(matches), hostname, strings.Join(ids, ", "))
+}
+
+// flattenServer converts a server and its IPs and BGP sessions to the
+// attributes described by dataSourceServerSchema.
+func flattenServer(server gona.Server, c apiClient, details bool) (map[string]interface{}, error) {
+	values := map[string]interface{}{
+		"hostname":    server.Name,
+		"package":     server.Package,
+		"plan_id":     server.PlanID,
+		"location_id": server.LocationID,
+		"image":       server.OS,
+		"image_id":    server.OSID,
+		"ip_v4":       server.PrimaryIPv4,
+		"ip_v6":       server.PrimaryIPv6,
+		"status":      server.ServerStatus,
+		"state":       server.PowerStatus,
+	}
+
+	// The IP addresses and BGP sessions take an API request each.
+	if !details {
+		return values, nil
+	}
+
+	ips, err := c.GetIPs(server.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	bgpSessions, err := c.GetBGPSessions(server.ID)
+	if err != nil {
+		return nil, err
+	}
 
 	if len(ips.IPv4) > 0 {
-		setValue("public_ipv4", ips.IPv4[0].IP, d, &diags)
+		values["public_ipv4"] = ips.IPv4[0].IP
 	}
 	if len(ips.IPv6) > 0 {
-		setValue("public_ipv6", ips.IPv6[0].IP, d, &diags)
+		values["public_ipv6"] = ips.IPv6[0].IP
 	}
+	values["ipv4_addresses"] = flattenIPs(ips.IPv4, gona.IPv4)
+	values["ipv6_addresses"] = flattenIPs(ips.IPv6, gona.IPv6)
 
 	if len(bgpSessions) > 0 {
 		var peerV4 []string
@@ -174,12 +270,83 @@ func dataSourceServerRead(ctx context.Context, d *schema.ResourceData, m interfa
 			bgpPeers["ipv6"] = peerV6
 		}
 
-		setValue("bgp_peers", []map[string]interface{}{bgpPeers}, d, &diags)
+		values["bgp_peers"] = []map[string]interface{}{bgpPeers}
 	}
 
-	if diags == nil {
-		d.SetId(strconv.Itoa(server.ID))
+	return values, nil
+}
+
+// ipAddressesSchema describes a computed list of the IP addresses assigned to
+// a server.
+func ipAddressesSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Computed: true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"address": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"netmask": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"prefix_length": {
+					Type:     schema.TypeInt,
+					Computed: true,
+				},
+				"gateway": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"reverse_dns": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"primary": {
+					Type:     schema.TypeBool,
+					Computed: true,
+				},
+			},
+		},
 	}
+}
 
-	return diags
+// flattenIPs converts IPs returned by GetIPs to the ipAddressesSchema format.
+func flattenIPs(ips []gona.IP, ipType gona.IPType) []map[string]interface{} {
+	result := make([]map[string]interface{}, len(ips))
+
+	for i, ip := range ips {
+		result[i] = map[string]interface{}{
+			"address":       ip.IP,
+			"netmask":       ip.Netmask,
+			"prefix_length": prefixLength(ip.Netmask, ipType),
+			"gateway":       ip.Gateway,
+			"reverse_dns":   ip.Reverse,
+			"primary":       ip.Primary == 1,
+		}
+	}
+
+	return result
+}
+
+// prefixLength converts a netmask to a prefix length. The API returns dotted
+// masks for IPv4 and bare prefix lengths (optionally with a leading slash) for
+// IPv6.
+func prefixLength(netmask string, ipType gona.IPType) int {
+	if length, err := strconv.Atoi(strings.TrimPrefix(netmask, "/")); err == nil {
+		return length
+	}
+
+	if ipType == gona.IPv4 {
+		if mask := net.ParseIP(netmask).To4(); mask != nil {
+			ones, bits := net.IPMask(mask).Size()
+			if bits != 0 {
+				return ones
+			}
+		}
+	}
+
+	return 0
 }
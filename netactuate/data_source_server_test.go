@@ -0,0 +1,62 @@
+package netactuate
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestDataSourceServerRead(t *testing.T) {
+	m := newMockAPI()
+	defer m.Close()
+
+	c := testClient(m)
+	sjc := m.addServer("web1.example.com", 1)
+	m.addServer("web2.example.com", 1)
+	ams := m.addServer("web2.example.com", 2)
+	idn := m.addServer("xn--bcher-kva.example.com", 1)
+
+	tests := []struct {
+		name   string
+		raw    map[string]interface{}
+		wantID int
+		err    string
+	}{
+		{"by id", map[string]interface{}{"id": sjc}, sjc, ""},
+		{"by hostname", map[string]interface{}{"hostname": "WEB1.example.com"}, sjc, ""},
+		{"by hostname and location", map[string]interface{}{"hostname": "web2.example.com", "location": "ams"}, ams, ""},
+		{"ambiguous hostname", map[string]interface{}{"hostname": "web2.example.com"}, 0, "Found 2 servers"},
+		{"by internationalized hostname", map[string]interface{}{"hostname": "bücher.example.com"}, idn, ""},
+		{"invalid hostname", map[string]interface{}{"hostname": "web_1.example.com"}, 0, "is not a valid hostname"},
+		{"unknown hostname", map[string]interface{}{"hostname": "web3.example.com"}, 0, "No server found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, dataSourceServer().Schema, tt.raw)
+
+			diags := dataSourceServerRead(context.Background(), d, c)
+			if tt.err != "" {
+				if !diags.HasError() || !strings.Contains(diags[0].Summary, tt.err) {
+					t.Fatalf("expected error containing %q, got %v", tt.err, diags)
+				}
+				return
+			}
+			if diags.HasError() {
+				t.Fatalf("unexpected error: %v", diags)
+			}
+
+			if got := d.Get("id").(int); got != tt.wantID {
+				t.Errorf("id: got %d, want %d", got, tt.wantID)
+			}
+			if got := d.Get("ipv4_addresses.0.prefix_length").(int); got != 24 {
+				t.Errorf("ipv4 prefix length: got %d, want 24", got)
+			}
+			if got := d.Get("ipv6_addresses.0.prefix_length").(int); got != 64 {
+				t.Errorf("ipv6 prefix length: got %d, want 64", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package netactuate
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/netactuate/gona/gona"
+)
+
+func dataSourceServers() *schema.Resource {
+	s := dataSourceServerSchema()
+	s["id"] = &schema.Schema{
+		Type:     schema.TypeInt,
+		Computed: true,
+	}
+
+	return &schema.Resource{
+		ReadContext: dataSourceServersRead,
+		Schema: map[string]*schema.Schema{
+			"hostname_regex": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringIsValidRegExp),
+				Description:      "Only return servers whose hostname matches this regular expression",
+			},
+			"location": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return servers in this location",
+			},
+			"plan": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return servers with this plan",
+			},
+			"status": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return servers with this status, e.g. RUNNING",
+			},
+			"state": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return servers with this power state",
+			},
+			"image": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return servers running this image",
+			},
+			"details": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Look up the IP addresses and BGP sessions of each server, which takes two API requests per server. When false, `public_ipv4`, `public_ipv6`, `ipv4_addresses`, `ipv6_addresses` and `bgp_peers` are left empty",
+			},
+			"servers": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: s,
+				},
+			},
+		},
+	}
+}
+
+func dataSourceServersRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(apiClient)
+
+	servers, err := c.GetServers()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	var hostnameRegex *regexp.Regexp
+	if v, ok := d.GetOk("hostname_regex"); ok {
+		hostnameRegex, err = regexp.Compile(v.(string))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	result := make([]map[string]interface{}, 0, len(servers))
+
+	for _, server := range servers {
+		if hostnameRegex != nil && !hostnameRegex.MatchString(server.Name) {
+			continue
+		}
+		if !matchServerLocation(server, d.Get("location").(string)) ||
+			!matchFilter(server.Package, d.Get("plan").(string)) ||
+			!matchFilter(server.ServerStatus, d.Get("status").(string)) ||
+			!matchFilter(server.PowerStatus, d.Get("state").(string)) ||
+			!matchFilter(server.OS, d.Get("image").(string)) {
+			continue
+		}
+
+		values, err := flattenServer(server, c, d.Get("details").(bool))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		values["id"] = server.ID
+
+		result = append(result, values)
+	}
+
+	err = d.Set("servers", result)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId("servers")
+
+	return nil
+}
+
+// matchFilter reports whether value matches an optional, case-insensitive filter.
+func matchFilter(value, filter string) bool {
+	return filter == "" || strings.EqualFold(value, filter)
+}
+
+// matchServerLocation compares the location filter the same way the server
+// resource does: by the first word of the location name.
+func matchServerLocation(server gona.Server, filter string) bool {
+	return locationCode(filter) == "" || locationCode(server.Location) == locationCode(filter)
+}
@@ -0,0 +1,99 @@
+package netactuate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/netactuate/gona/gona"
+)
+
+func TestDataSourceServersRead(t *testing.T) {
+	m := newMockAPI()
+	defer m.Close()
+
+	c := testClient(m)
+	m.addServer("web1.example.com", 1)
+	m.addServer("web2.example.com", 2)
+	m.addServer("db1.example.com", 2)
+
+	tests := []struct {
+		name    string
+		filters map[string]interface{}
+		want    int
+	}{
+		{"no filters", map[string]interface{}{}, 3},
+		{"hostname regex", map[string]interface{}{"hostname_regex": `^web\d+\.`}, 2},
+		{"location", map[string]interface{}{"location": "AMS"}, 2},
+		{"hostname regex and location", map[string]interface{}{"hostname_regex": "^web", "location": "ams"}, 1},
+		{"status", map[string]interface{}{"status": "running"}, 3},
+		{"image", map[string]interface{}{"image": "Debian 12 (20230612)"}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, dataSourceServers().Schema, tt.filters)
+
+			if diags := dataSourceServersRead(context.Background(), d, c); diags.HasError() {
+				t.Fatalf("unexpected error: %v", diags)
+			}
+			if got := d.Get("servers.#").(int); got != tt.want {
+				t.Errorf("got %d servers, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// countingClient counts the per-server requests of the servers data source.
+type countingClient struct {
+	apiClient
+	calls int
+}
+
+func (c *countingClient) GetIPs(id int) (gona.IPs, error) {
+	c.calls++
+	return c.apiClient.GetIPs(id)
+}
+
+func (c *countingClient) GetBGPSessions(id int) ([]*gona.BGPSession, error) {
+	c.calls++
+	return c.apiClient.GetBGPSessions(id)
+}
+
+func TestDataSourceServersDetails(t *testing.T) {
+	m := newMockAPI()
+	defer m.Close()
+
+	m.addServer("web1.example.com", 1)
+	m.addServer("web2.example.com", 2)
+
+	tests := []struct {
+		name      string
+		raw       map[string]interface{}
+		wantCalls int
+		wantIPs   int
+	}{
+		{name: "default", raw: map[string]interface{}{}, wantCalls: 4, wantIPs: 1},
+		{name: "without details", raw: map[string]interface{}{"details": false}, wantCalls: 0, wantIPs: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &countingClient{apiClient: testClient(m)}
+			d := schema.TestResourceDataRaw(t, dataSourceServers().Schema, tt.raw)
+
+			if diags := dataSourceServersRead(context.Background(), d, c); diags.HasError() {
+				t.Fatalf("unexpected error: %v", diags)
+			}
+			if c.calls != tt.wantCalls {
+				t.Errorf("expected %d per-server requests, got %d", tt.wantCalls, c.calls)
+			}
+			if got := d.Get("servers.0.ipv4_addresses.#").(int); got != tt.wantIPs {
+				t.Errorf("expected %d IPv4 addresses, got %d", tt.wantIPs, got)
+			}
+			if got := d.Get("servers.0.hostname").(string); got == "" {
+				t.Error("expected the hostname without details too")
+			}
+		})
+	}
+}
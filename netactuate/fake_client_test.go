@@ -0,0 +1,74 @@
+package netactuate
+
+import (
+	"time"
+
+	"github.com/netactuate/gona/gona"
+)
+
+// fakeResponse is one scripted GetServer answer.
+type fakeResponse struct {
+	server gona.Server
+	err    error
+	delay  time.Duration
+}
+
+// fakeClient is a scripted apiClient for unit tests. GetServer plays back
+// responses in order and keeps repeating the last one; methods that aren't
+// overridden panic through the nil embedded interface.
+type fakeClient struct {
+	apiClient
+
+	responses []fakeResponse
+	calls     int
+
+	locations    []gona.Location
+	locationsErr error
+	oss          []gona.OS
+	ossErr       error
+	apiCalls     int
+}
+
+func (f *fakeClient) GetServer(id int) (gona.Server, error) {
+	r := f.responses[min(f.calls, len(f.responses)-1)]
+	f.calls++
+	time.Sleep(r.delay)
+	r.server.ID = id
+	return r.server, r.err
+}
+
+func (f *fakeClient) GetLocations() ([]gona.Location, error) {
+	f.apiCalls++
+	return f.locations, f.locationsErr
+}
+
+func (f *fakeClient) GetOSs() ([]gona.OS, error) {
+	f.apiCalls++
+	return f.oss, f.ossErr
+}
+
+// status returns n scripted responses with the given server status.
+func status(s string, n int) []fakeResponse {
+	r := make([]fakeResponse, n)
+	for i := range r {
+		r[i].server.ServerStatus = s
+	}
+	return r
+}
+
+// failures returns n scripted responses that fail with err.
+func failures(err error, n int) []fakeResponse {
+	r := make([]fakeResponse, n)
+	for i := range r {
+		r[i].err = err
+	}
+	return r
+}
+
+func script(parts ...[]fakeResponse) []fakeResponse {
+	var r []fakeResponse
+	for _, part := range parts {
+		r = append(r, part...)
+	}
+	return r
+}
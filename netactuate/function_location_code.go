@@ -0,0 +1,51 @@
+package netactuate
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+var _ function.Function = &locationCodeFunction{}
+
+// locationCodeFunction is provider::netactuate::location_code, the same
+// normalisation the server resource applies to location.
+type locationCodeFunction struct{}
+
+func newLocationCodeFunction() function.Function {
+	return &locationCodeFunction{}
+}
+
+func (f *locationCodeFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "location_code"
+}
+
+func (f *locationCodeFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:     "Short code of a location",
+		Description: `Returns the upper-cased first word of a location name, e.g. "SJC" for "sjc" or "SJC - San Jose, CA". This is the value netactuate_server stores in location.`,
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "name",
+				Description: "Location code or full location name",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *locationCodeFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var name string
+	resp.Error = req.Arguments.Get(ctx, &name)
+	if resp.Error != nil {
+		return
+	}
+
+	code := locationCode(name)
+	if code == "" {
+		resp.Error = function.NewArgumentFuncError(0, "Location name must not be empty")
+		return
+	}
+
+	resp.Error = resp.Result.Set(ctx, code)
+}
@@ -0,0 +1,30 @@
+package netactuate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestLocationCodeFunction(t *testing.T) {
+	tests := map[string]string{
+		"sjc":                 "SJC",
+		"SJC - San Jose, CA":  "SJC",
+		"ams - Amsterdam, NL": "AMS",
+	}
+
+	for name, want := range tests {
+		got, err := runFunction(t, newLocationCodeFunction(), types.StringValue(name))
+		if err != nil {
+			t.Fatalf("%q: %v", name, err)
+		}
+		if !got.Equal(types.StringValue(want)) {
+			t.Errorf("%q: got %v, want %q", name, got, want)
+		}
+	}
+
+	if _, err := runFunction(t, newLocationCodeFunction(), types.StringValue(" ")); err == nil || !strings.Contains(err.Error(), "must not be empty") {
+		t.Errorf("expected an empty name to fail, got %v", err)
+	}
+}
@@ -0,0 +1,72 @@
+package netactuate
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ function.Function = &planSpecFunction{}
+
+var planSpecAttrTypes = map[string]attr.Type{
+	"cpu":  types.Int64Type,
+	"ram":  types.Int64Type,
+	"disk": types.Int64Type,
+}
+
+// planSpecFunction is provider::netactuate::plan_spec, which splits a plan
+// name into its vCPU count, RAM and disk size.
+type planSpecFunction struct{}
+
+type planSpecModel struct {
+	CPU  types.Int64 `tfsdk:"cpu"`
+	RAM  types.Int64 `tfsdk:"ram"`
+	Disk types.Int64 `tfsdk:"disk"`
+}
+
+func newPlanSpecFunction() function.Function {
+	return &planSpecFunction{}
+}
+
+func (f *planSpecFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "plan_spec"
+}
+
+func (f *planSpecFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:     "vCPU, RAM and disk of a plan",
+		Description: `Splits a plan name such as "VR2x2x25" into an object with cpu (vCPU count), ram (GB) and disk (GB).`,
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "name",
+				Description: `Plan name in the form "VR<cpu>x<ram>x<disk>"`,
+			},
+		},
+		Return: function.ObjectReturn{
+			AttributeTypes: planSpecAttrTypes,
+		},
+	}
+}
+
+func (f *planSpecFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var name string
+	resp.Error = req.Arguments.Get(ctx, &name)
+	if resp.Error != nil {
+		return
+	}
+
+	cpu, ram, disk, ok := planSpec(name)
+	if !ok {
+		resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("Plan %q doesn't follow the VR<cpu>x<ram>x<disk> naming convention", name))
+		return
+	}
+
+	resp.Error = resp.Result.Set(ctx, planSpecModel{
+		CPU:  types.Int64Value(int64(cpu)),
+		RAM:  types.Int64Value(int64(ram)),
+		Disk: types.Int64Value(int64(disk)),
+	})
+}
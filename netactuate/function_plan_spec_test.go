@@ -0,0 +1,29 @@
+package netactuate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestPlanSpecFunction(t *testing.T) {
+	got, err := runFunction(t, newPlanSpecFunction(), types.StringValue("VR2x4x50"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := types.ObjectValueMust(planSpecAttrTypes, map[string]attr.Value{
+		"cpu":  types.Int64Value(2),
+		"ram":  types.Int64Value(4),
+		"disk": types.Int64Value(50),
+	})
+	if !got.Equal(want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	if _, err := runFunction(t, newPlanSpecFunction(), types.StringValue("custom")); err == nil || !strings.Contains(err.Error(), "naming convention") {
+		t.Errorf("expected an unconventional plan name to fail, got %v", err)
+	}
+}
@@ -0,0 +1,51 @@
+package netactuate
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+var _ function.Function = &validateHostnameFunction{}
+
+// validateHostnameFunction is provider::netactuate::validate_hostname, the
+// check netactuate_server runs on hostname.
+type validateHostnameFunction struct{}
+
+func newValidateHostnameFunction() function.Function {
+	return &validateHostnameFunction{}
+}
+
+func (f *validateHostnameFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "validate_hostname"
+}
+
+func (f *validateHostnameFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:     "Validate a server hostname",
+		Description: "Checks that a hostname or FQDN is valid for netactuate_server and returns it as sent to the API, with internationalized labels in punycode. Fails with the reason for invalid hostnames.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "hostname",
+				Description: "RFC 1123 hostname or FQDN",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *validateHostnameFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var hostname string
+	resp.Error = req.Arguments.Get(ctx, &hostname)
+	if resp.Error != nil {
+		return
+	}
+
+	ascii, err := hostnameToASCII(hostname)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, err.Error())
+		return
+	}
+
+	resp.Error = resp.Result.Set(ctx, ascii)
+}
@@ -0,0 +1,30 @@
+package netactuate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestValidateHostnameFunction(t *testing.T) {
+	tests := map[string]string{
+		"web1.example.com":  "web1.example.com",
+		"bücher.example.de": "xn--bcher-kva.example.de",
+	}
+
+	for hostname, want := range tests {
+		got, err := runFunction(t, newValidateHostnameFunction(), types.StringValue(hostname))
+		if err != nil {
+			t.Fatalf("%q: %v", hostname, err)
+		}
+		if !got.Equal(types.StringValue(want)) {
+			t.Errorf("%q: got %v, want %q", hostname, got, want)
+		}
+	}
+
+	_, err := runFunction(t, newValidateHostnameFunction(), types.StringValue("web_1.example.com"))
+	if err == nil || !strings.Contains(err.Error(), "is not a valid hostname") {
+		t.Errorf("expected an invalid hostname to fail, got %v", err)
+	}
+}
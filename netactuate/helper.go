@@ -1,6 +1,8 @@
 package netactuate
 
 import (
+	"strings"
+
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -18,3 +20,13 @@ func updateValue(key string, value interface{}, d *schema.ResourceData, diags *d
 		setValue(key, value, d, diags)
 	}
 }
+
+// locationCode returns the upper-cased first word of a location name, e.g.
+// "SJC" for "SJC - San Jose, CA", or an empty string for a blank name.
+func locationCode(name string) string {
+	fields := strings.Fields(name)
+	if len(fields) == 0 {
+		return ""
+	}
+	return strings.ToUpper(fields[0])
+}
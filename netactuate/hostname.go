@@ -0,0 +1,135 @@
+package netactuate
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"golang.org/x/net/idna"
+)
+
+const (
+	maxHostnameLength = 253
+	maxLabelLength    = 63
+)
+
+// hostnameError describes why a hostname is invalid. Label is the 1-based
+// position of the offending label, or 0 when the hostname as a whole is at
+// fault.
+type hostnameError struct {
+	Hostname string
+	Label    int
+	Value    string
+	Reason   string
+}
+
+func (e *hostnameError) Error() string {
+	if e.Label == 0 {
+		return fmt.Sprintf("%q is not a valid hostname: %s", e.Hostname, e.Reason)
+	}
+	return fmt.Sprintf("%q is not a valid hostname: label %d (%q) %s", e.Hostname, e.Label, e.Value, e.Reason)
+}
+
+// hostnameToASCII validates an RFC 1123 hostname and returns its ASCII form.
+// Labels containing non-ASCII characters are converted to punycode, and
+// labels already in punycode must decode cleanly.
+func hostnameToASCII(hostname string) (string, error) {
+	if hostname == "" {
+		return "", &hostnameError{Hostname: hostname, Reason: "must not be empty"}
+	}
+	if strings.HasSuffix(hostname, ".") {
+		return "", &hostnameError{Hostname: hostname, Reason: "must not end with a dot"}
+	}
+
+	labels := strings.Split(hostname, ".")
+	ascii := make([]string, len(labels))
+
+	for i, label := range labels {
+		labelErr := func(format string, a ...interface{}) error {
+			return &hostnameError{Hostname: hostname, Label: i + 1, Value: label, Reason: fmt.Sprintf(format, a...)}
+		}
+
+		if label == "" {
+			return "", labelErr("is empty")
+		}
+
+		a := label
+		if !utf8.ValidString(label) {
+			return "", labelErr("is not valid UTF-8")
+		}
+		if !isASCII(label) {
+			var err error
+			a, err = idna.Lookup.ToASCII(label)
+			if err != nil {
+				return "", labelErr("is not a valid internationalized label: %s", err)
+			}
+			// IDNA mapping drops default-ignorable code points such as soft
+			// hyphens, which can leave nothing behind.
+			if a == "" {
+				return "", labelErr("is empty once ignored characters are removed")
+			}
+		} else if strings.HasPrefix(strings.ToLower(label), "xn--") {
+			if _, err := idna.Lookup.ToUnicode(label); err != nil {
+				return "", labelErr("is not valid punycode: %s", err)
+			}
+		}
+
+		if len(a) > maxLabelLength {
+			return "", labelErr("is %d characters long, the maximum is %d", len(a), maxLabelLength)
+		}
+		for _, r := range a {
+			if !isLDH(r) {
+				return "", labelErr("contains invalid character %q, only letters, digits and hyphens are allowed", r)
+			}
+		}
+		if strings.HasPrefix(a, "-") || strings.HasSuffix(a, "-") {
+			return "", labelErr("must not start or end with a hyphen")
+		}
+
+		ascii[i] = a
+	}
+
+	result := strings.Join(ascii, ".")
+	if len(result) > maxHostnameLength {
+		return "", &hostnameError{Hostname: hostname, Reason: fmt.Sprintf("is %d characters long, the maximum is %d", len(result), maxHostnameLength)}
+	}
+
+	return result, nil
+}
+
+// asciiHostname returns the ASCII form of a hostname that has already passed
+// validateHostname.
+func asciiHostname(hostname string) string {
+	if ascii, err := hostnameToASCII(hostname); err == nil {
+		return ascii
+	}
+	return hostname
+}
+
+func validateHostname(i interface{}, path cty.Path) diag.Diagnostics {
+	if _, err := hostnameToASCII(i.(string)); err != nil {
+		return diag.Diagnostics{{
+			Severity:      diag.Error,
+			Summary:       "Invalid hostname",
+			Detail:        err.Error(),
+			AttributePath: path,
+		}}
+	}
+	return nil
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
+
+// isLDH reports whether r is a letter, digit or hyphen.
+func isLDH(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-'
+}
@@ -0,0 +1,166 @@
+package netactuate
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/go-cty/cty"
+)
+
+func TestHostnameToASCII(t *testing.T) {
+	tests := []struct {
+		name      string
+		hostname  string
+		want      string
+		wantLabel int
+		wantErr   string
+	}{
+		{name: "single label", hostname: "web1", want: "web1"},
+		{name: "fqdn", hostname: "web1.example.com", want: "web1.example.com"},
+		{name: "case is preserved", hostname: "Web1.Example.COM", want: "Web1.Example.COM"},
+		{name: "leading digit", hostname: "1web.example.com", want: "1web.example.com"},
+		{name: "inner hyphens", hostname: "a--b.example.com", want: "a--b.example.com"},
+		{name: "longest label", hostname: strings.Repeat("a", 63) + ".com", want: strings.Repeat("a", 63) + ".com"},
+		{name: "longest hostname", hostname: longHostname(253), want: longHostname(253)},
+		{name: "unicode label", hostname: "bücher.example.com", want: "xn--bcher-kva.example.com"},
+		{name: "unicode is mapped to lower case", hostname: "BÜCHER.example.com", want: "xn--bcher-kva.example.com"},
+		{name: "punycode label", hostname: "xn--bcher-kva.example.com", want: "xn--bcher-kva.example.com"},
+
+		{name: "empty", hostname: "", wantErr: "must not be empty"},
+		{name: "trailing dot", hostname: "web1.example.com.", wantErr: "must not end with a dot"},
+		{name: "leading dot", hostname: ".example.com", wantLabel: 1, wantErr: "is empty"},
+		{name: "empty label", hostname: "web1..com", wantLabel: 2, wantErr: "is empty"},
+		{name: "label too long", hostname: "web1." + strings.Repeat("a", 64) + ".com", wantLabel: 2, wantErr: "is 64 characters long, the maximum is 63"},
+		{name: "hostname too long", hostname: longHostname(254), wantErr: "is 254 characters long, the maximum is 253"},
+		{name: "leading hyphen", hostname: "web1.-example.com", wantLabel: 2, wantErr: "must not start or end with a hyphen"},
+		{name: "trailing hyphen", hostname: "web1-.example.com", wantLabel: 1, wantErr: "must not start or end with a hyphen"},
+		{name: "underscore", hostname: "web_1.example.com", wantLabel: 1, wantErr: `invalid character '_'`},
+		{name: "space", hostname: "web 1.example.com", wantLabel: 1, wantErr: `invalid character ' '`},
+		{name: "suffix of a valid name", hostname: "%%%.example.com", wantLabel: 1, wantErr: `invalid character '%'`},
+		{name: "bad punycode", hostname: "xn--a.example.com", wantLabel: 1, wantErr: "is not valid punycode"},
+		{name: "mixed direction label", hostname: "web1.aא1.com", wantLabel: 2, wantErr: "is not a valid internationalized label"},
+		{name: "invalid utf-8", hostname: "web1.0\xbc0.com", wantLabel: 2, wantErr: "is not valid UTF-8"},
+		{name: "soft hyphen label", hostname: "a.\u00ad.com", wantLabel: 2, wantErr: "is empty once ignored characters are removed"},
+		{name: "soft hyphen hostname", hostname: "\u00ad", wantLabel: 1, wantErr: "is empty once ignored characters are removed"},
+		{name: "zero width space label", hostname: "a.\u200b.com", wantLabel: 2, wantErr: "is empty"},
+		{name: "variation selector label", hostname: "a.\ufe0f.com", wantLabel: 2, wantErr: "is empty"},
+		{name: "unicode label too long", hostname: strings.Repeat("ü", 60) + ".com", wantLabel: 1, wantErr: "the maximum is 63"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := hostnameToASCII(tt.hostname)
+
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if got != tt.want {
+					t.Errorf("got %q, want %q", got, tt.want)
+				}
+				return
+			}
+
+			var herr *hostnameError
+			if !errors.As(err, &herr) {
+				t.Fatalf("expected a hostnameError, got %v", err)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %q", tt.wantErr, err)
+			}
+			if herr.Label != tt.wantLabel {
+				t.Errorf("expected label %d to fail, got %d (%v)", tt.wantLabel, herr.Label, err)
+			}
+		})
+	}
+}
+
+func TestValidateHostname(t *testing.T) {
+	path := cty.GetAttrPath("hostname")
+
+	if diags := validateHostname("web1.example.com", path); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+
+	diags := validateHostname("web1.bad_label.com", path)
+	if !diags.HasError() {
+		t.Fatal("expected an error")
+	}
+	if !strings.Contains(diags[0].Detail, `label 2 ("bad_label")`) {
+		t.Errorf("expected the detail to name the failing label, got %q", diags[0].Detail)
+	}
+	if !diags[0].AttributePath.Equals(path) {
+		t.Errorf("expected attribute path %v, got %v", path, diags[0].AttributePath)
+	}
+}
+
+func FuzzHostnameToASCII(f *testing.F) {
+	for _, seed := range []string{
+		"web1",
+		"web1.example.com",
+		"bücher.example.com",
+		"xn--bcher-kva.example.com",
+		"web1..com",
+		"a.\u00ad.com",
+		"\u00ad",
+		"web\u00ad1.example.com",
+		"a.\u200b.com",
+		"a.\ufe0f.com",
+		"-web1.example.com",
+		"web1.example.com.",
+		longHostname(253),
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, hostname string) {
+		ascii, err := hostnameToASCII(hostname)
+		if err != nil {
+			return
+		}
+
+		if len(ascii) > maxHostnameLength {
+			t.Fatalf("%q: result %q is longer than %d", hostname, ascii, maxHostnameLength)
+		}
+		for _, label := range strings.Split(ascii, ".") {
+			if label == "" {
+				t.Fatalf("%q: result %q has an empty label", hostname, ascii)
+			}
+			if len(label) > maxLabelLength {
+				t.Fatalf("%q: result %q has a label of length %d", hostname, ascii, len(label))
+			}
+			if strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+				t.Fatalf("%q: result %q has a label with a leading or trailing hyphen", hostname, ascii)
+			}
+			for _, r := range label {
+				if !isLDH(r) {
+					t.Fatalf("%q: result %q contains %q", hostname, ascii, r)
+				}
+			}
+		}
+
+		again, err := hostnameToASCII(ascii)
+		if err != nil {
+			t.Fatalf("%q: result %q does not validate: %v", hostname, ascii, err)
+		}
+		if again != ascii {
+			t.Fatalf("%q: not idempotent, %q became %q", hostname, ascii, again)
+		}
+	})
+}
+
+// longHostname returns a valid hostname of exactly n characters.
+func longHostname(n int) string {
+	var labels []string
+	for n > 0 {
+		l := min(n, maxLabelLength)
+		if n-l == 1 {
+			// Avoid leaving a single character for the separator.
+			l--
+		}
+		labels = append(labels, strings.Repeat("a", l))
+		n -= l + 1
+	}
+	return strings.Join(labels, ".")
+}
@@ -0,0 +1,521 @@
+package netactuate
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/netactuate/gona/gona"
+)
+
+// mockAPI is an in-memory fake of the parts of the NetActuate API used by the
+// provider. Servers move through BUILDING -> RUNNING and DELETING ->
+// TERMINATED after transitionPolls reads, so wait4Status is exercised the same
+// way as against the real API.
+type mockAPI struct {
+	mu     sync.Mutex
+	server *httptest.Server
+
+	transitionPolls int
+
+	locations []gona.Location
+	images    []gona.OS
+	plans     []gona.Plan
+
+	nextID      int
+	servers     map[int]*mockServer
+	sshKeys     map[int]*gona.SSHKey
+	bgpSessions map[int]*gona.BGPSession
+}
+
+type mockServer struct {
+	gona.Server
+	ips gona.IPs
+
+	// pending is the status the server moves to after polls reads; cancelled
+	// servers disappear entirely once they reach TERMINATED.
+	pending   string
+	polls     int
+	cancelled bool
+}
+
+const mockAPIPrefix = "/api/"
+
+func newMockAPI() *mockAPI {
+	m := &mockAPI{
+		transitionPolls: 2,
+		locations: []gona.Location{
+			{ID: 1, Name: "SJC - San Jose, CA", IATACode: "SJC", Continent: "North America"},
+			{ID: 2, Name: "AMS - Amsterdam, NL", IATACode: "AMS", Continent: "Europe"},
+			{ID: 3, Name: "LHR - London, UK", IATACode: "LHR", Continent: "Europe", Disabled: 1},
+		},
+		images: []gona.OS{
+			{ID: 10, Os: "Ubuntu 22.04 (20221110)", Type: "linux", Bits: "64"},
+			{ID: 11, Os: "Debian 12 (20230612)", Type: "linux", Bits: "64"},
+		},
+		plans: []gona.Plan{
+			{ID: 100, Name: "VR1x1x25", RAM: "1024", Disk: "25", Transfer: "1000", Price: "5.00", Available: "1"},
+			{ID: 101, Name: "VR2x2x50", RAM: "2048", Disk: "50", Transfer: "2000", Price: "10.00", Available: "1"},
+			{ID: 102, Name: "VR4x4x100", RAM: "4096", Disk: "100", Transfer: "4000", Price: "20.00", Available: "0"},
+		},
+		nextID:      1000,
+		servers:     make(map[int]*mockServer),
+		sshKeys:     make(map[int]*gona.SSHKey),
+		bgpSessions: make(map[int]*gona.BGPSession),
+	}
+	m.server = httptest.NewServer(http.HandlerFunc(m.serveHTTP))
+	return m
+}
+
+// URL returns the value to use as the provider's api_url.
+func (m *mockAPI) URL() string {
+	return m.server.URL + mockAPIPrefix
+}
+
+func (m *mockAPI) Close() {
+	m.server.Close()
+}
+
+func (m *mockAPI) id() int {
+	m.nextID++
+	return m.nextID
+}
+
+// addServer creates a RUNNING server directly, bypassing the build transition.
+func (m *mockAPI) addServer(fqdn string, locationID int) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s := &mockServer{}
+	s.ID = m.id()
+	s.Package = m.plans[0].Name
+	s.PlanID = m.plans[0].ID
+	err := m.install(s, url.Values{
+		"fqdn":     {fqdn},
+		"location": {strconv.Itoa(locationID)},
+		"image":    {strconv.Itoa(m.images[0].ID)},
+	})
+	if err != nil {
+		panic(err)
+	}
+	s.ServerStatus = s.pending
+	s.pending = ""
+	m.servers[s.ID] = s
+
+	return s.ID
+}
+
+func (m *mockAPI) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if r.URL.Query().Get("key") == "" {
+		writeMockError(w, http.StatusUnauthorized, "missing API key", nil)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		writeMockError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, mockAPIPrefix)
+	route := r.Method + " " + path
+	id, _ := strconv.Atoi(path[strings.LastIndex(path, "/")+1:])
+
+	switch {
+	case route == "GET cloud/locations":
+		writeMockData(w, m.locations)
+	case route == "GET cloud/images":
+		writeMockData(w, m.images)
+	case route == "GET cloud/sizes":
+		writeMockData(w, m.plans)
+	case route == "GET cloud/servers":
+		writeMockData(w, m.listServers())
+	case route == "GET cloud/server":
+		m.getServer(w, r)
+	case route == "POST cloud/server/buy_build":
+		m.buyBuild(w, r)
+	case strings.HasPrefix(route, "POST cloud/server/build/"):
+		m.build(w, r, id)
+	case route == "POST cloud/server/delete":
+		m.deleteServer(w, r)
+	case strings.HasPrefix(route, "POST cloud/server/unlink/"):
+		m.unlink(w, id)
+	case strings.HasPrefix(route, "POST cloud/server/start/"):
+		m.setPower(w, id, "running")
+	case strings.HasPrefix(route, "POST cloud/server/shutdown/"):
+		m.setPower(w, id, "stopped")
+	case strings.HasPrefix(route, "GET cloud/networkips/"):
+		m.getIPs(w, id)
+	case route == "GET account/ssh_keys":
+		writeMockData(w, m.listSSHKeys())
+	case strings.HasPrefix(route, "GET account/ssh_key/"):
+		m.getSSHKey(w, id)
+	case route == "POST account/ssh_key":
+		m.createSSHKey(w, r)
+	case strings.HasPrefix(route, "DELETE account/ssh_key/"):
+		m.deleteSSHKey(w, id)
+	case route == "GET bgp/bgpsessions":
+		writeMockData(w, m.listBGPSessions())
+	case strings.HasPrefix(route, "GET bgp/bgpsession/"):
+		m.getBGPSession(w, id)
+	case route == "POST bgp/bgpcreatesessions":
+		m.createBGPSessions(w, r)
+	default:
+		writeMockError(w, http.StatusNotFound, "no such endpoint: "+route, nil)
+	}
+}
+
+func (m *mockAPI) listServers() []gona.Server {
+	servers := make([]gona.Server, 0, len(m.servers))
+	for _, id := range sortedKeys(m.servers) {
+		servers = append(servers, m.servers[id].Server)
+	}
+	return servers
+}
+
+func (m *mockAPI) getServer(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(r.Form.Get("mbpkgid"))
+	s, ok := m.servers[id]
+	if !ok {
+		// The real API answers unknown packages with a 422 on the mbpkgid
+		// field, which gona passes through as an empty server.
+		writeMockError(w, http.StatusUnprocessableEntity, "invalid mbpkgid",
+			map[string]interface{}{"mbpkgid": "mbpkgid must be a valid mbpkgid"})
+		return
+	}
+
+	if s.pending != "" {
+		s.polls++
+		if s.polls >= m.transitionPolls {
+			s.ServerStatus = s.pending
+			s.pending = ""
+			if s.ServerStatus == "TERMINATED" && s.cancelled {
+				delete(m.servers, id)
+			}
+		}
+	}
+
+	writeMockData(w, s.Server)
+}
+
+func (m *mockAPI) buyBuild(w http.ResponseWriter, r *http.Request) {
+	s := &mockServer{}
+	s.ID = m.id()
+	s.Package = r.Form.Get("plan")
+	s.PackageBilling = r.Form.Get("package_billing")
+	s.PackageBillingContractId = r.Form.Get("package_billing_contract_id")
+
+	for _, plan := range m.plans {
+		if plan.Name == s.Package {
+			s.PlanID = plan.ID
+		}
+	}
+	if s.PlanID == 0 {
+		writeMockError(w, http.StatusUnprocessableEntity, "invalid plan",
+			map[string]interface{}{"plan": "plan is invalid"})
+		return
+	}
+
+	if err := m.install(s, r.Form); err != nil {
+		writeMockError(w, http.StatusUnprocessableEntity, err.Error(), map[string]interface{}{"location": err.Error()})
+		return
+	}
+	m.servers[s.ID] = s
+
+	writeMockData(w, gona.ServerBuild{ServerID: s.ID, Status: "ok", Build: 1})
+}
+
+func (m *mockAPI) build(w http.ResponseWriter, r *http.Request, id int) {
+	s, ok := m.servers[id]
+	if !ok || s.cancelled {
+		writeMockError(w, http.StatusNotFound, "no such server", nil)
+		return
+	}
+	if s.ServerStatus != "TERMINATED" {
+		writeMockError(w, http.StatusUnprocessableEntity, "server must be terminated before rebuilding",
+			map[string]interface{}{"status": s.ServerStatus})
+		return
+	}
+
+	if err := m.install(s, r.Form); err != nil {
+		writeMockError(w, http.StatusUnprocessableEntity, err.Error(), map[string]interface{}{"location": err.Error()})
+		return
+	}
+
+	writeMockData(w, gona.ServerBuild{ServerID: s.ID, Status: "ok", Build: 1})
+}
+
+// install applies build parameters to a server and starts the BUILDING ->
+// RUNNING transition.
+func (m *mockAPI) install(s *mockServer, form url.Values) error {
+	locationID, _ := strconv.Atoi(form.Get("location"))
+	if s.LocationID != 0 && s.LocationID != locationID {
+		return fmt.Errorf("server must be unlinked before moving to another location")
+	}
+
+	var location *gona.Location
+	for i := range m.locations {
+		if m.locations[i].ID == locationID {
+			location = &m.locations[i]
+		}
+	}
+	if location == nil || location.Disabled != 0 {
+		return fmt.Errorf("invalid location %d", locationID)
+	}
+
+	imageID, _ := strconv.Atoi(form.Get("image"))
+	var image *gona.OS
+	for i := range m.images {
+		if m.images[i].ID == imageID {
+			image = &m.images[i]
+		}
+	}
+	if image == nil {
+		return fmt.Errorf("invalid image %d", imageID)
+	}
+
+	s.Name = form.Get("fqdn")
+	s.LocationID = location.ID
+	s.Location = location.Name
+	s.OSID = image.ID
+	s.OS = image.Os
+	s.Installed = 1
+	s.PowerStatus = "running"
+	s.ServerStatus = "BUILDING"
+	s.pending = "RUNNING"
+	s.polls = 0
+
+	s.PrimaryIPv4 = fmt.Sprintf("192.0.2.%d", s.ID%250+1)
+	s.PrimaryIPv6 = fmt.Sprintf("2001:db8::%x", s.ID)
+	s.ips = gona.IPs{
+		IPv4: []gona.IP{{
+			ID: s.ID, Primary: 1, IP: s.PrimaryIPv4, Netmask: "255.255.255.0",
+			Gateway: "192.0.2.254", Broadcast: "192.0.2.255", Reverse: s.Name,
+		}},
+		IPv6: []gona.IP{{
+			ID: s.ID, Primary: 1, IP: s.PrimaryIPv6, Netmask: "64", Gateway: "2001:db8::1", Reverse: s.Name,
+		}},
+	}
+
+	return nil
+}
+
+func (m *mockAPI) deleteServer(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(r.URL.Query().Get("mbpkgid"))
+	s, ok := m.servers[id]
+	if !ok {
+		writeMockError(w, http.StatusNotFound, "no such server", nil)
+		return
+	}
+
+	s.Installed = 0
+	s.PowerStatus = "stopped"
+	s.ServerStatus = "DELETING"
+	s.pending = "TERMINATED"
+	s.polls = 0
+	s.cancelled = r.PostForm.Get("cancel_billing") == "1"
+	s.ips = gona.IPs{}
+
+	for sessionID, session := range m.bgpSessions {
+		if session.CustomerIP == s.PrimaryIPv4 || session.CustomerIP == s.PrimaryIPv6 {
+			delete(m.bgpSessions, sessionID)
+		}
+	}
+
+	writeMockData(w, nil)
+}
+
+func (m *mockAPI) unlink(w http.ResponseWriter, id int) {
+	s, ok := m.servers[id]
+	if !ok {
+		writeMockError(w, http.StatusNotFound, "no such server", nil)
+		return
+	}
+	if s.Installed != 0 {
+		writeMockError(w, http.StatusUnprocessableEntity, "server must be deleted before unlinking",
+			map[string]interface{}{"status": s.ServerStatus})
+		return
+	}
+
+	s.LocationID = 0
+	s.Location = ""
+
+	writeMockData(w, nil)
+}
+
+func (m *mockAPI) setPower(w http.ResponseWriter, id int, state string) {
+	s, ok := m.servers[id]
+	if !ok {
+		writeMockError(w, http.StatusNotFound, "no such server", nil)
+		return
+	}
+
+	s.PowerStatus = state
+
+	writeMockData(w, nil)
+}
+
+func (m *mockAPI) getIPs(w http.ResponseWriter, id int) {
+	s, ok := m.servers[id]
+	if !ok {
+		writeMockError(w, http.StatusNotFound, "no such server", nil)
+		return
+	}
+
+	writeMockData(w, s.ips)
+}
+
+func (m *mockAPI) listSSHKeys() []gona.SSHKey {
+	keys := make([]gona.SSHKey, 0, len(m.sshKeys))
+	for _, id := range sortedKeys(m.sshKeys) {
+		keys = append(keys, *m.sshKeys[id])
+	}
+	return keys
+}
+
+func (m *mockAPI) getSSHKey(w http.ResponseWriter, id int) {
+	key, ok := m.sshKeys[id]
+	if !ok {
+		writeMockError(w, http.StatusNotFound, "no such SSH key", nil)
+		return
+	}
+
+	writeMockData(w, key)
+}
+
+func (m *mockAPI) createSSHKey(w http.ResponseWriter, r *http.Request) {
+	key := &gona.SSHKey{
+		ID:          m.id(),
+		Name:        r.PostForm.Get("name"),
+		Key:         r.PostForm.Get("ssh_key"),
+		Fingerprint: mockFingerprint(r.PostForm.Get("ssh_key")),
+	}
+	m.sshKeys[key.ID] = key
+
+	writeMockData(w, key)
+}
+
+func (m *mockAPI) deleteSSHKey(w http.ResponseWriter, id int) {
+	if _, ok := m.sshKeys[id]; !ok {
+		writeMockError(w, http.StatusNotFound, "no such SSH key", nil)
+		return
+	}
+	delete(m.sshKeys, id)
+
+	writeMockData(w, nil)
+}
+
+func (m *mockAPI) listBGPSessions() []*gona.BGPSession {
+	sessions := make([]*gona.BGPSession, 0, len(m.bgpSessions))
+	for _, id := range sortedKeys(m.bgpSessions) {
+		sessions = append(sessions, m.bgpSessions[id])
+	}
+	return sessions
+}
+
+func (m *mockAPI) getBGPSession(w http.ResponseWriter, id int) {
+	session, ok := m.bgpSessions[id]
+	if !ok {
+		writeMockError(w, http.StatusNotFound, "no such BGP session", nil)
+		return
+	}
+
+	writeMockData(w, session)
+}
+
+func (m *mockAPI) createBGPSessions(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(r.PostForm.Get("mbpkgid"))
+	groupID, _ := strconv.Atoi(r.PostForm.Get("group_id"))
+
+	s, ok := m.servers[id]
+	if !ok || s.Installed == 0 {
+		writeMockError(w, http.StatusUnprocessableEntity, "invalid mbpkgid",
+			map[string]interface{}{"group_id": "server is not running"})
+		return
+	}
+
+	newSession := func(customerIP, providerIP string, ipType gona.IPType) *gona.BGPSession {
+		session := &gona.BGPSession{
+			ID:             m.id(),
+			CustomerIP:     customerIP,
+			ProviderPeerIP: providerIP,
+			ProviderIPType: string(ipType),
+			GroupID:        groupID,
+			GroupName:      fmt.Sprintf("group-%d", groupID),
+			Location:       s.Location,
+			CustomerAsn:    64512,
+			ProviderAsn:    36236,
+			State:          "Established",
+		}
+		m.bgpSessions[session.ID] = session
+		return session
+	}
+
+	redundant := r.PostForm.Get("redundant") == "1"
+
+	session := newSession(s.PrimaryIPv4, "192.0.2.253", gona.IPv4)
+	if redundant {
+		newSession(s.PrimaryIPv4, "192.0.2.254", gona.IPv4)
+	}
+	if r.PostForm.Get("ipv6") == "1" {
+		newSession(s.PrimaryIPv6, "2001:db8::fffe", gona.IPv6)
+		if redundant {
+			newSession(s.PrimaryIPv6, "2001:db8::ffff", gona.IPv6)
+		}
+	}
+
+	writeMockData(w, session)
+}
+
+func writeMockData(w http.ResponseWriter, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"result": "success",
+		"code":   http.StatusOK,
+		"data":   data,
+	})
+}
+
+func writeMockError(w http.ResponseWriter, code int, message string, fields map[string]interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"result":  "error",
+		"code":    code,
+		"message": message,
+		"fields":  fields,
+	})
+}
+
+// mockFingerprint returns the OpenSSH SHA256 fingerprint of an authorized_keys
+// line, or an empty string if it can't be parsed.
+func mockFingerprint(key string) string {
+	fields := strings.Fields(key)
+	if len(fields) < 2 {
+		return ""
+	}
+	blob, err := base64.StdEncoding.DecodeString(fields[1])
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(blob)
+	return "SHA256:" + base64.RawStdEncoding.EncodeToString(sum[:])
+}
+
+func sortedKeys[V any](m map[int]V) []int {
+	keys := make([]int, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Ints(keys)
+	return keys
+}
@@ -2,6 +2,8 @@ package netactuate
 
 import (
 	"context"
+	"net/http"
+	"os"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -19,17 +21,21 @@ func Provider() *schema.Provider {
 			"api_url": {
 				Type:        schema.TypeString,
 				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("NETACTUATE_API_URL", nil),
 			},
 		},
+		// netactuate_sshkey is served by the framework provider, see
+		// ProviderServer.
 		ResourcesMap: map[string]*schema.Resource{
 			"netactuate_server":       resourceServer(),
-			"netactuate_sshkey":       resourceSshKey(),
 			"netactuate_bgp_sessions": resourceBGPSessions(),
 		},
 		DataSourcesMap: map[string]*schema.Resource{
 			"netactuate_server":       dataSourceServer(),
 			"netactuate_sshkey":       dataSourceSshKey(),
 			"netactuate_bgp_sessions": dataSourceBGPSessions(),
+			"netactuate_plans":        dataSourcePlans(),
+			"netactuate_servers":      dataSourceServers(),
 		},
 		ConfigureContextFunc: providerConfigure,
 	}
@@ -41,20 +47,47 @@ func providerConfigure(ctx context.Context, d *schema.ResourceData) (interface{}
 	apiKey := d.Get("api_key").(string)
 	apiUrl := d.Get("api_url").(string)
 
-	if apiKey == "" {
+	if apiKey == "" && apiKeyRequired() {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
-			Summary:  "Unable to create NetActuate API client",
-			Detail: `Unable to find NetActuate API key. It can be set with either NETACTUATE_API_KEY environment
-variable or 'api_key' property`,
+			Summary:  clientErrorSummary,
+			Detail:   missingAPIKeyDetail,
 		})
 		return nil, diags
 	}
 
+	client, err := newClient(apiKey, apiUrl)
+	if err != nil {
+		return nil, diag.FromErr(err)
+	}
+
+	return client, nil
+}
+
+const (
+	clientErrorSummary  = "Unable to create NetActuate API client"
+	missingAPIKeyDetail = `Unable to find NetActuate API key. It can be set with either NETACTUATE_API_KEY environment
+variable or 'api_key' property`
+)
+
+// newClient returns the API client shared by the SDK and framework providers.
+func newClient(apiKey, apiUrl string) (*gona.Client, error) {
+	var client *gona.Client
 	if apiUrl == "" {
-		return gona.NewClient(apiKey), nil
+		client = gona.NewClient(apiKey)
 	} else {
-		return gona.NewClientCustom(apiKey, apiUrl), nil
+		client = gona.NewClientCustom(apiKey, apiUrl)
+	}
+
+	// gona sends its requests through http.DefaultClient, so that's where
+	// the record/replay transport has to go.
+	if mode := os.Getenv(cassetteModeEnv); mode != "" {
+		transport, err := cassetteTransportFor(mode, os.Getenv(cassettePathEnv), http.DefaultClient.Transport)
+		if err != nil {
+			return nil, err
+		}
+		http.DefaultClient.Transport = transport
 	}
 
+	return client, nil
 }
@@ -0,0 +1,467 @@
+package netactuate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+// The acceptance tests run real Terraform plans and applies against the mock
+// API, so they need TF_ACC=1 and a terraform binary but no credentials. Without
+// TF_ACC they are skipped, so plain go test only runs the unit tests:
+//
+//	TF_ACC=1 go test ./netactuate/
+
+// testAccProviderFactories serves the provider the way main.go does, with the
+// SDKv2 and framework resources behind one mux server.
+var testAccProviderFactories = map[string]func() (tfprotov5.ProviderServer, error){
+	"netactuate": func() (tfprotov5.ProviderServer, error) {
+		serverFactory, err := ProviderServer(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		return serverFactory(), nil
+	},
+}
+
+func TestAccServer_basic(t *testing.T) {
+	m := newMockAPI()
+	defer m.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV5ProviderFactories: testAccProviderFactories,
+		CheckDestroy:             testAccCheckServerDestroy(m),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccServerConfig(m, testAccPrefix+"-web1.example.com", "SJC"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckServerStatus(m, "netactuate_server.test", "RUNNING"),
+					resource.TestCheckResourceAttr("netactuate_server.test", "hostname", testAccPrefix+"-web1.example.com"),
+					resource.TestCheckResourceAttr("netactuate_server.test", "location", "SJC"),
+					resource.TestCheckResourceAttrSet("netactuate_server.test", "primary_ipv4"),
+					resource.TestCheckResourceAttr("netactuate_server.test", "ipv4_addresses.#", "1"),
+					resource.TestCheckResourceAttr("netactuate_server.test", "ipv6_addresses.0.prefix_length", "64"),
+				),
+			},
+			{
+				// Moving to another location deletes, unlinks and rebuilds the
+				// same package.
+				Config: testAccServerConfig(m, testAccPrefix+"-web2.example.com", "AMS"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckServerStatus(m, "netactuate_server.test", "RUNNING"),
+					resource.TestCheckResourceAttr("netactuate_server.test", "hostname", testAccPrefix+"-web2.example.com"),
+					resource.TestCheckResourceAttr("netactuate_server.test", "location", "AMS"),
+				),
+			},
+			{
+				ResourceName:            "netactuate_server.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"params", "ssh_key"},
+			},
+			{
+				ResourceName:            "netactuate_server.test",
+				ImportState:             true,
+				ImportStateId:           "hostname:" + testAccPrefix + "-web2.example.com/AMS",
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"params", "ssh_key"},
+			},
+		},
+	})
+}
+
+// TestAccServer_importGeneratedConfig imports a server that Terraform didn't
+// create using the configuration terraform plan -generate-config-out writes
+// for it, and checks that it plans without changes.
+func TestAccServer_importGeneratedConfig(t *testing.T) {
+	m := newMockAPI()
+	defer m.Close()
+
+	id := m.addServer(testAccPrefix+"-import.example.com", 2)
+	m.mu.Lock()
+	m.servers[id].PackageBilling = "usage"
+	m.servers[id].PackageBillingContractId = "1234"
+	m.mu.Unlock()
+
+	config := testAccProviderConfig(m) + fmt.Sprintf(`
+resource "netactuate_server" "test" {
+  cloud_config                = null
+  hostname                    = "%s-import.example.com"
+  image                       = "Ubuntu 22.04 (20221110)"
+  image_id                    = 10
+  location                    = "AMS"
+  location_id                 = 2
+  package_billing             = "usage"
+  package_billing_contract_id = "1234"
+  package_billing_opt_in      = null
+  params                      = null
+  password                    = null # sensitive
+  plan                        = "VR1x1x25"
+  ssh_key                     = null
+  ssh_key_id                  = null
+  user_data                   = null
+  user_data_base64            = null
+}
+`, testAccPrefix)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV5ProviderFactories: testAccProviderFactories,
+		CheckDestroy:             testAccCheckServerDestroy(m),
+		Steps: []resource.TestStep{
+			{
+				Config:             config,
+				ResourceName:       "netactuate_server.test",
+				ImportState:        true,
+				ImportStateId:      strconv.Itoa(id),
+				ImportStatePersist: true,
+			},
+			{
+				Config:   config,
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestAccServer_unavailableLocation(t *testing.T) {
+	m := newMockAPI()
+	defer m.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV5ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccServerConfig(m, testAccPrefix+"-web1.example.com", "LHR"),
+				ExpectError: regexp.MustCompile(`Location "LHR - London, UK" is disabled`),
+			},
+		},
+	})
+}
+
+func testAccServerConfig(m *mockAPI, hostname, location string) string {
+	return testAccProviderConfig(m) + fmt.Sprintf(`
+resource "netactuate_server" "test" {
+  hostname                    = %q
+  plan                        = "VR1x1x25"
+  location                    = %q
+  image                       = "Ubuntu 22.04 (20221110)"
+  ssh_key                     = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIGb0 test"
+  package_billing_contract_id = "1234"
+}
+`, hostname, location)
+}
+
+func testAccCheckServerStatus(m *mockAPI, name, status string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("not found: %s", name)
+		}
+
+		id, err := strconv.Atoi(rs.Primary.ID)
+		if err != nil {
+			return err
+		}
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		server, ok := m.servers[id]
+		if !ok {
+			return fmt.Errorf("server %d doesn't exist", id)
+		}
+		if server.ServerStatus != status {
+			return fmt.Errorf("server %d has status %q, want %q", id, server.ServerStatus, status)
+		}
+		return nil
+	}
+}
+
+func testAccCheckServerDestroy(m *mockAPI) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		for _, rs := range s.RootModule().Resources {
+			if rs.Type != "netactuate_server" {
+				continue
+			}
+			id, err := strconv.Atoi(rs.Primary.ID)
+			if err != nil {
+				return err
+			}
+			if _, ok := m.servers[id]; ok {
+				return fmt.Errorf("server %d still exists", id)
+			}
+		}
+		return nil
+	}
+}
+
+func TestAccSshKey_basic(t *testing.T) {
+	m := newMockAPI()
+	defer m.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV5ProviderFactories: testAccProviderFactories,
+		CheckDestroy:             testAccCheckSshKeyDestroy(m),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccSshKeyConfig(m, testAccPrefix+"-key"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("netactuate_sshkey.test", "name", testAccPrefix+"-key"),
+					resource.TestCheckResourceAttr("netactuate_sshkey.test", "key", testSSHKey),
+					resource.TestCheckResourceAttrPair("data.netactuate_sshkey.test", "name", "netactuate_sshkey.test", "name"),
+					resource.TestCheckResourceAttr("data.netactuate_sshkey.test", "fingerprint", mockFingerprint(testSSHKey)),
+				),
+			},
+			{
+				ResourceName:            "netactuate_sshkey.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"last_updated"},
+			},
+			{
+				ResourceName:            "netactuate_sshkey.test",
+				ImportState:             true,
+				ImportStateId:           "fingerprint:" + mockFingerprint(testSSHKey),
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"last_updated"},
+			},
+		},
+	})
+}
+
+// testAccRequiredProviders declares the provider, which calling its functions
+// requires. The test harness serves it under the hashicorp namespace.
+const testAccRequiredProviders = `
+terraform {
+  required_providers {
+    netactuate = {
+      source = "hashicorp/netactuate"
+    }
+  }
+}
+`
+
+func TestAccFunctions_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheckTerraformVersion(t, "1.8.0") },
+		ProtoV5ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				// Functions don't need the provider to be configured.
+				Config: testAccRequiredProviders + `
+output "location" {
+  value = provider::netactuate::location_code("sjc - San Jose, CA")
+}
+
+output "cpu" {
+  value = tostring(provider::netactuate::plan_spec("VR2x4x50").cpu)
+}
+
+output "hostname" {
+  value = provider::netactuate::validate_hostname("bücher.example.de")
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckOutput("location", "SJC"),
+					resource.TestCheckOutput("cpu", "2"),
+					resource.TestCheckOutput("hostname", "xn--bcher-kva.example.de"),
+				),
+			},
+			{
+				Config: testAccRequiredProviders + `
+output "hostname" {
+  value = provider::netactuate::validate_hostname("web_1.example.com")
+}
+`,
+				ExpectError: regexp.MustCompile(`is not a valid\s+hostname`),
+			},
+		},
+	})
+}
+
+// testAccPreCheckTerraformVersion skips the test when the terraform binary
+// the tests run with is older than min. Without a binary the tests install
+// the latest release, which is new enough.
+func testAccPreCheckTerraformVersion(t *testing.T, min string) {
+	path := os.Getenv("TF_ACC_TERRAFORM_PATH")
+	if path == "" {
+		var err error
+		if path, err = exec.LookPath("terraform"); err != nil {
+			return
+		}
+	}
+
+	out, err := exec.Command(path, "version", "-json").Output()
+	if err != nil {
+		t.Fatalf("terraform version: %v", err)
+	}
+	var v struct {
+		Version string `json:"terraform_version"`
+	}
+	if err := json.Unmarshal(out, &v); err != nil {
+		t.Fatalf("terraform version: %v", err)
+	}
+
+	if version.Must(version.NewVersion(v.Version)).LessThan(version.Must(version.NewVersion(min))) {
+		t.Skipf("needs Terraform %s or later, got %s", min, v.Version)
+	}
+}
+
+func TestAccBGPSessions_basic(t *testing.T) {
+	m := newMockAPI()
+	defer m.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV5ProviderFactories: testAccProviderFactories,
+		CheckDestroy:             testAccCheckServerDestroy(m),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccServerConfig(m, testAccPrefix+"-bgp1.example.com", "SJC") + `
+resource "netactuate_bgp_sessions" "test" {
+  mbpkgid  = netactuate_server.test.id
+  group_id = 42
+}
+
+data "netactuate_bgp_sessions" "test" {
+  mbpkgid = netactuate_bgp_sessions.test.mbpkgid
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair("netactuate_bgp_sessions.test", "id", "netactuate_server.test", "id"),
+					resource.TestCheckResourceAttr("data.netactuate_bgp_sessions.test", "sessions.#", "2"),
+					resource.TestCheckResourceAttr("data.netactuate_bgp_sessions.test", "sessions.0.group_id", "42"),
+					resource.TestCheckResourceAttr("data.netactuate_bgp_sessions.test", "sessions.0.provider_ip_type", "ipv4"),
+					resource.TestCheckResourceAttr("data.netactuate_bgp_sessions.test", "sessions.1.provider_ip_type", "ipv6"),
+				),
+			},
+			{
+				ResourceName:      "netactuate_bgp_sessions.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateIdFunc: func(s *terraform.State) (string, error) {
+					return s.RootModule().Resources["netactuate_bgp_sessions.test"].Primary.ID + "/42", nil
+				},
+			},
+		},
+	})
+}
+
+func TestAccDataSourceServer_basic(t *testing.T) {
+	m := newMockAPI()
+	defer m.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV5ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccServerConfig(m, testAccPrefix+"-web1.example.com", "SJC") + `
+data "netactuate_server" "by_id" {
+  id = netactuate_server.test.id
+}
+
+data "netactuate_server" "by_hostname" {
+  hostname = netactuate_server.test.hostname
+  location = "sjc"
+}
+
+data "netactuate_servers" "running" {
+  hostname_regex = "^tf-acc-test-web[0-9]+\\."
+  status         = "RUNNING"
+
+  depends_on = [netactuate_server.test]
+}
+
+data "netactuate_plans" "test" {
+  name = netactuate_server.test.plan
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair("data.netactuate_server.by_id", "ip_v4", "netactuate_server.test", "primary_ipv4"),
+					resource.TestCheckResourceAttrPair("data.netactuate_server.by_hostname", "id", "netactuate_server.test", "id"),
+					resource.TestCheckResourceAttr("data.netactuate_server.by_id", "ipv6_addresses.#", "1"),
+					resource.TestCheckResourceAttr("data.netactuate_servers.running", "servers.#", "1"),
+					resource.TestCheckResourceAttr("data.netactuate_servers.running", "servers.0.hostname", testAccPrefix+"-web1.example.com"),
+					resource.TestCheckResourceAttr("data.netactuate_plans.test", "plans.0.vcpu", "1"),
+					resource.TestCheckResourceAttr("data.netactuate_plans.test", "plans.0.available", "true"),
+					resource.TestCheckResourceAttr("data.netactuate_plans.test", "enabled_locations.#", "2"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccDataSourceServer_hostnameNotFound(t *testing.T) {
+	m := newMockAPI()
+	defer m.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV5ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(m) + `
+data "netactuate_server" "test" {
+  hostname = "tf-acc-test-missing.example.com"
+}
+`,
+				ExpectError: regexp.MustCompile(`No server found with hostname`),
+			},
+		},
+	})
+}
+
+// testAccProviderConfig points the provider at the mock API.
+func testAccProviderConfig(m *mockAPI) string {
+	return fmt.Sprintf(`
+provider "netactuate" {
+  api_key = "test"
+  api_url = %q
+}
+`, m.URL())
+}
+
+func testAccSshKeyConfig(m *mockAPI, name string) string {
+	return testAccProviderConfig(m) + fmt.Sprintf(`
+resource "netactuate_sshkey" "test" {
+  name = %q
+  key  = %q
+}
+
+data "netactuate_sshkey" "test" {
+  id = netactuate_sshkey.test.id
+}
+`, name, testSSHKey)
+}
+
+func testAccCheckSshKeyDestroy(m *mockAPI) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		for _, rs := range s.RootModule().Resources {
+			if rs.Type != "netactuate_sshkey" {
+				continue
+			}
+			id, err := strconv.Atoi(rs.Primary.ID)
+			if err != nil {
+				return err
+			}
+			if _, ok := m.sshKeys[id]; ok {
+				return fmt.Errorf("SSH key %d still exists", id)
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,116 @@
+package netactuate
+
+import (
+	"context"
+	"os"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-mux/tf5muxserver"
+)
+
+// ProviderServer serves the SDKv2 provider and the framework provider as a
+// single provider. Resources move to the framework one at a time; each type
+// name must only be served by one of them.
+func ProviderServer(ctx context.Context) (func() tfprotov5.ProviderServer, error) {
+	providers := []func() tfprotov5.ProviderServer{
+		Provider().GRPCProvider,
+		providerserver.NewProtocol5(FrameworkProvider()),
+	}
+
+	muxServer, err := tf5muxserver.NewMuxServer(ctx, providers...)
+	if err != nil {
+		return nil, err
+	}
+
+	return muxServer.ProviderServer, nil
+}
+
+var _ provider.ProviderWithFunctions = &frameworkProvider{}
+
+type frameworkProvider struct{}
+
+type frameworkProviderModel struct {
+	APIKey types.String `tfsdk:"api_key"`
+	APIURL types.String `tfsdk:"api_url"`
+}
+
+// FrameworkProvider returns the terraform-plugin-framework half of the
+// provider. Its schema must match Provider's, as the mux server sends the
+// same configuration to both.
+func FrameworkProvider() provider.Provider {
+	return &frameworkProvider{}
+}
+
+func (p *frameworkProvider) Metadata(ctx context.Context, req provider.MetadataRequest, resp *provider.MetadataResponse) {
+	resp.TypeName = "netactuate"
+}
+
+func (p *frameworkProvider) Schema(ctx context.Context, req provider.SchemaRequest, resp *provider.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"api_key": schema.StringAttribute{
+				Optional: true,
+			},
+			"api_url": schema.StringAttribute{
+				Optional: true,
+			},
+		},
+	}
+}
+
+func (p *frameworkProvider) Configure(ctx context.Context, req provider.ConfigureRequest, resp *provider.ConfigureResponse) {
+	var config frameworkProviderModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	apiKey := config.APIKey.ValueString()
+	if config.APIKey.IsNull() {
+		apiKey = os.Getenv("NETACTUATE_API_KEY")
+	}
+	apiUrl := config.APIURL.ValueString()
+	if config.APIURL.IsNull() {
+		apiUrl = os.Getenv("NETACTUATE_API_URL")
+	}
+
+	if apiKey == "" && apiKeyRequired() {
+		resp.Diagnostics.AddError(clientErrorSummary, missingAPIKeyDetail)
+		return
+	}
+
+	client, err := newClient(apiKey, apiUrl)
+	if err != nil {
+		resp.Diagnostics.AddError(clientErrorSummary, err.Error())
+		return
+	}
+
+	resp.ResourceData = apiClient(client)
+	resp.DataSourceData = apiClient(client)
+}
+
+func (p *frameworkProvider) Resources(ctx context.Context) []func() resource.Resource {
+	return []func() resource.Resource{
+		newSshKeyResource,
+	}
+}
+
+func (p *frameworkProvider) DataSources(ctx context.Context) []func() datasource.DataSource {
+	return nil
+}
+
+// Functions need Terraform 1.8 or later; older versions ignore them.
+func (p *frameworkProvider) Functions(ctx context.Context) []func() function.Function {
+	return []func() function.Function{
+		newLocationCodeFunction,
+		newPlanSpecFunction,
+		newValidateHostnameFunction,
+	}
+}
@@ -0,0 +1,135 @@
+package netactuate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/netactuate/gona/gona"
+)
+
+func TestProvider(t *testing.T) {
+	if err := Provider().InternalValidate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestProviderConfigure(t *testing.T) {
+	m := newMockAPI()
+	defer m.Close()
+
+	p := Provider()
+	diags := p.Configure(context.Background(), terraform.NewResourceConfigRaw(map[string]interface{}{
+		"api_key": "test",
+		"api_url": m.URL(),
+	}))
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if _, ok := p.Meta().(*gona.Client); !ok {
+		t.Fatalf("expected a *gona.Client, got %T", p.Meta())
+	}
+}
+
+func TestProviderConfigureMissingKey(t *testing.T) {
+	t.Setenv("NETACTUATE_API_KEY", "")
+
+	diags := Provider().Configure(context.Background(), terraform.NewResourceConfigRaw(map[string]interface{}{}))
+	if !diags.HasError() {
+		t.Fatal("expected an error without an API key")
+	}
+}
+
+// testClient returns an API client talking to the mock API.
+func testClient(m *mockAPI) *gona.Client {
+	return gona.NewClientCustom("test", m.URL())
+}
+
+func TestProviderServer(t *testing.T) {
+	// The mux server rejects providers whose schemas differ, and resource
+	// types served by both.
+	serverFactory, err := ProviderServer(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := serverFactory().GetProviderSchema(context.Background(), &tfprotov5.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range resp.Diagnostics {
+		t.Errorf("%s: %s", d.Summary, d.Detail)
+	}
+	for _, name := range []string{"netactuate_server", "netactuate_sshkey", "netactuate_bgp_sessions"} {
+		if _, ok := resp.ResourceSchemas[name]; !ok {
+			t.Errorf("expected resource %s to be served", name)
+		}
+	}
+	for _, name := range []string{"location_code", "plan_spec", "validate_hostname"} {
+		if _, ok := resp.Functions[name]; !ok {
+			t.Errorf("expected function %s to be served", name)
+		}
+	}
+}
+
+// runFunction calls a provider function the way the framework does, starting
+// from an unknown result.
+func runFunction(t *testing.T, f function.Function, args ...attr.Value) (attr.Value, *function.FuncError) {
+	t.Helper()
+	ctx := context.Background()
+
+	var def function.DefinitionResponse
+	f.Definition(ctx, function.DefinitionRequest{}, &def)
+	if def.Diagnostics.HasError() {
+		t.Fatalf("definition: %v", def.Diagnostics)
+	}
+
+	result, funcErr := def.Definition.Return.NewResultData(ctx)
+	if funcErr != nil {
+		t.Fatalf("result: %v", funcErr)
+	}
+
+	resp := function.RunResponse{Result: result}
+	f.Run(ctx, function.RunRequest{Arguments: function.NewArgumentsData(args)}, &resp)
+	return resp.Result.Value(), resp.Error
+}
+
+func TestFrameworkProviderConfigure(t *testing.T) {
+	m := newMockAPI()
+	defer m.Close()
+
+	t.Setenv("NETACTUATE_API_KEY", "test")
+	t.Setenv("NETACTUATE_API_URL", m.URL())
+
+	ctx := context.Background()
+	serverFactory, err := ProviderServer(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := serverFactory()
+
+	schemaResp, err := server.GetProviderSchema(ctx, &tfprotov5.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	configType := schemaResp.Provider.ValueType()
+	config, err := tfprotov5.NewDynamicValue(configType, tftypes.NewValue(configType, map[string]tftypes.Value{
+		"api_key": tftypes.NewValue(tftypes.String, nil),
+		"api_url": tftypes.NewValue(tftypes.String, nil),
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := server.ConfigureProvider(ctx, &tfprotov5.ConfigureProviderRequest{Config: &config})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range resp.Diagnostics {
+		t.Errorf("%s: %s", d.Summary, d.Detail)
+	}
+}
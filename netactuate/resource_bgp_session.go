@@ -2,7 +2,10 @@ package netactuate
 
 import (
 	"context"
+	"fmt"
+	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -15,7 +18,7 @@ func resourceBGPSessions() *schema.Resource {
 		ReadContext:   resourceBGPSessionRead,
 		DeleteContext: resourceBGPSessionDelete,
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: resourceBGPSessionImport,
 		},
 		Schema: map[string]*schema.Schema{
 			"mbpkgid": {
@@ -45,7 +48,7 @@ func resourceBGPSessions() *schema.Resource {
 }
 
 func resourceBGPSessionCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*gona.Client)
+	c := m.(apiClient)
 
 	_, err := c.CreateBGPSessions(d.Get("mbpkgid").(int), d.Get("group_id").(int), d.Get("ipv6").(bool),
 		d.Get("redundant").(bool))
@@ -67,3 +70,77 @@ func resourceBGPSessionDelete(ctx context.Context, d *schema.ResourceData, m int
 	// Do nothing
 	return nil
 }
+
+// resourceBGPSessionImport accepts <mbpkgid>/<group_id>, or a bare mbpkgid
+// when all of the server's sessions belong to one group. Read doesn't query
+// the API, so the arguments are recovered from the existing sessions here.
+func resourceBGPSessionImport(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	c := m.(apiClient)
+
+	idPart, groupPart, hasGroup := strings.Cut(d.Id(), "/")
+	id, err := strconv.Atoi(idPart)
+	if err != nil || id <= 0 {
+		return nil, fmt.Errorf("Invalid BGP sessions import ID %q, expected <mbpkgid>/<group_id> or <mbpkgid>", d.Id())
+	}
+	groupID := 0
+	if hasGroup {
+		groupID, err = strconv.Atoi(groupPart)
+		if err != nil || groupID <= 0 {
+			return nil, fmt.Errorf("Invalid BGP sessions import ID %q, expected <mbpkgid>/<group_id> or <mbpkgid>", d.Id())
+		}
+	}
+
+	sessions, err := c.GetBGPSessions(id)
+	if err != nil {
+		return nil, fmt.Errorf("Could not import BGP sessions for server %d: %w", id, err)
+	}
+
+	groups := make(map[int][]*gona.BGPSession)
+	for _, session := range sessions {
+		groups[session.GroupID] = append(groups[session.GroupID], session)
+	}
+	groupIDs := make([]int, 0, len(groups))
+	for group := range groups {
+		groupIDs = append(groupIDs, group)
+	}
+	sort.Ints(groupIDs)
+
+	if !hasGroup {
+		switch len(groups) {
+		case 0:
+			return nil, fmt.Errorf("No BGP sessions found for server %d", id)
+		case 1:
+			groupID = groupIDs[0]
+		default:
+			return nil, fmt.Errorf("Server %d has BGP sessions in groups %v, import with <mbpkgid>/<group_id> instead",
+				id, groupIDs)
+		}
+	}
+
+	group, ok := groups[groupID]
+	if !ok {
+		return nil, fmt.Errorf("No BGP sessions found for server %d in group %d", id, groupID)
+	}
+
+	// Redundant sessions peer with a second provider router per address family.
+	var ipv4, ipv6 int
+	for _, session := range group {
+		if session.IsProviderIPTypeV4() {
+			ipv4++
+		} else {
+			ipv6++
+		}
+	}
+
+	var diags diag.Diagnostics
+	d.SetId(strconv.Itoa(id))
+	setValue("mbpkgid", id, d, &diags)
+	setValue("group_id", groupID, d, &diags)
+	setValue("ipv6", ipv6 > 0, d, &diags)
+	setValue("redundant", ipv4 > 1, d, &diags)
+	if diags.HasError() {
+		return nil, fmt.Errorf("%s", diags[0].Summary)
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
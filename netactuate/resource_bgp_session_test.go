@@ -0,0 +1,124 @@
+package netactuate
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestBGPSessionCreate(t *testing.T) {
+	m := newMockAPI()
+	defer m.Close()
+
+	ctx := context.Background()
+	c := testClient(m)
+	id := m.addServer("bgp1.example.com", 1)
+
+	d := schema.TestResourceDataRaw(t, resourceBGPSessions().Schema, map[string]interface{}{
+		"mbpkgid":  id,
+		"group_id": 42,
+	})
+
+	if diags := resourceBGPSessionCreate(ctx, d, c); diags.HasError() {
+		t.Fatalf("create: %v", diags)
+	}
+	if d.Id() != strconv.Itoa(id) {
+		t.Errorf("id: got %q, want %d", d.Id(), id)
+	}
+
+	sessions, err := c.GetBGPSessions(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 2 {
+		t.Fatalf("expected an IPv4 and an IPv6 session, got %d", len(sessions))
+	}
+}
+
+func TestBGPSessionImport(t *testing.T) {
+	m := newMockAPI()
+	defer m.Close()
+
+	c := testClient(m)
+	single := m.addServer("bgp1.example.com", 1)
+	redundant := m.addServer("bgp2.example.com", 1)
+	multi := m.addServer("bgp3.example.com", 2)
+	none := m.addServer("bgp4.example.com", 2)
+
+	for _, s := range []struct {
+		id, group       int
+		ipv6, redundant bool
+	}{
+		{single, 42, true, false},
+		{redundant, 42, false, true},
+		{multi, 42, true, false},
+		{multi, 43, true, false},
+	} {
+		if _, err := c.CreateBGPSessions(s.id, s.group, s.ipv6, s.redundant); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		id      string
+		wantID  int
+		want    map[string]interface{}
+		wantErr string
+	}{
+		{
+			name:   "mbpkgid and group",
+			id:     fmt.Sprintf("%d/42", single),
+			wantID: single,
+			want:   map[string]interface{}{"mbpkgid": single, "group_id": 42, "ipv6": true, "redundant": false},
+		},
+		{
+			name:   "mbpkgid with a single group",
+			id:     strconv.Itoa(redundant),
+			wantID: redundant,
+			want:   map[string]interface{}{"mbpkgid": redundant, "group_id": 42, "ipv6": false, "redundant": true},
+		},
+		{
+			name:   "one of several groups",
+			id:     fmt.Sprintf("%d/43", multi),
+			wantID: multi,
+			want:   map[string]interface{}{"group_id": 43},
+		},
+		{name: "several groups", id: strconv.Itoa(multi), wantErr: "has BGP sessions in groups [42 43]"},
+		{name: "unknown group", id: fmt.Sprintf("%d/44", single), wantErr: "in group 44"},
+		{name: "no sessions", id: strconv.Itoa(none), wantErr: "No BGP sessions found"},
+		{name: "not a number", id: "bgp1", wantErr: "Invalid BGP sessions import ID"},
+		{name: "bad group", id: fmt.Sprintf("%d/x", single), wantErr: "Invalid BGP sessions import ID"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := resourceBGPSessions().TestResourceData()
+			d.SetId(tt.id)
+
+			result, err := resourceBGPSessionImport(context.Background(), d, c)
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(result) != 1 || result[0].Id() != strconv.Itoa(tt.wantID) {
+				t.Fatalf("expected id %d, got %v", tt.wantID, result)
+			}
+			for key, want := range tt.want {
+				if got := result[0].Get(key); got != want {
+					t.Errorf("%s: got %v, want %v", key, got, want)
+				}
+			}
+		})
+	}
+}
@@ -4,12 +4,10 @@ import (
 	"context"
 	"encoding/base64"
 	"fmt"
-	"regexp"
 	"strconv"
 	"strings"
 	"time"
 
-	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -21,13 +19,14 @@ const (
 	intervalSec = 1
 )
 
+// pollInterval is the delay between status checks in wait4Status.
+var pollInterval = intervalSec * time.Second
+
 var (
 	credentialKeys = []string{"password", "ssh_key_id", "ssh_key"}
 	locationKeys   = []string{"location", "location_id"}
 	imageKeys      = []string{"image", "image_id"}
 	billingKeys    = []string{"package_billing_contract_id", "package_billing_opt_in"}
-
-	hostnameRegex = fmt.Sprintf("(%[1]s\\.)*%[1]s$", fmt.Sprintf("(%[1]s|%[1]s%[2]s*%[1]s)", "[a-zA-Z0-9]", "[a-zA-Z0-9\\-]"))
 )
 
 func resourceServer() *schema.Resource {
@@ -37,24 +36,28 @@ func resourceServer() *schema.Resource {
 		UpdateContext: resourceServerUpdate,
 		DeleteContext: resourceServerDelete,
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: resourceServerImport,
+		},
+		SchemaVersion: 1,
+		StateUpgraders: []schema.StateUpgrader{
+			{
+				Version: 0,
+				Type:    resourceServerV0Type(),
+				Upgrade: resourceServerStateUpgradeV0,
+			},
 		},
 		Schema: map[string]*schema.Schema{
 			"hostname": {
-				Type:     schema.TypeString,
-				ForceNew: false,
-				Required: true,
-				ValidateDiagFunc: func(i interface{}, path cty.Path) diag.Diagnostics {
-					var diags diag.Diagnostics
-
-					match, err := regexp.MatchString(hostnameRegex, i.(string))
-					if err != nil {
-						diags = diag.FromErr(err)
-					} else if !match {
-						diags = diag.Errorf("%q is not a valid hostname", i)
-					}
-
-					return diags
+				Type:             schema.TypeString,
+				ForceNew:         false,
+				Required:         true,
+				ValidateDiagFunc: validateHostname,
+				Description:      "RFC 1123 hostname or FQDN of the server. Internationalized labels are sent to the API in punycode",
+				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
+					// The API stores internationalized hostnames in punycode.
+					a, errOld := hostnameToASCII(old)
+					b, errNew := hostnameToASCII(new)
+					return errOld == nil && errNew == nil && a == b
 				},
 			},
 			"plan": {
@@ -69,27 +72,27 @@ func resourceServer() *schema.Resource {
 				Default:  "usage",
 			},
 			"package_billing_opt_in": {
-				Type:         schema.TypeString,
-				ExactlyOneOf: billingKeys,
-				ForceNew:     false,
-				Optional:     true,
+				Type:          schema.TypeString,
+				ConflictsWith: []string{"package_billing_contract_id"},
+				ForceNew:      false,
+				Optional:      true,
 			},
 			"package_billing_contract_id": {
-				Type:         schema.TypeString,
-				ExactlyOneOf: billingKeys,
-				ForceNew:     false,
-				Optional:     true,
+				Type:          schema.TypeString,
+				ConflictsWith: []string{"package_billing_opt_in"},
+				ForceNew:      false,
+				Optional:      true,
 			},
 			"location": {
 				Type:         schema.TypeString,
 				ForceNew:     false,
 				Optional:     true,
-				ExactlyOneOf: locationKeys,
+				AtLeastOneOf: locationKeys,
 				StateFunc: func(val any) string {
 					return strings.ToUpper(val.(string))
 				},
 				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
-					if new == "" || strings.EqualFold(strings.ToUpper(old), strings.Fields(new)[0]) {
+					if new == "" || locationCode(old) == locationCode(new) {
 						return true
 					}
 					return false
@@ -99,39 +102,43 @@ func resourceServer() *schema.Resource {
 				Type:         schema.TypeInt,
 				ForceNew:     false,
 				Optional:     true,
-				ExactlyOneOf: locationKeys,
+				AtLeastOneOf: locationKeys,
 				Computed:     true,
 			},
 			"image": {
 				Type:         schema.TypeString,
 				ForceNew:     false,
 				Optional:     true,
-				ExactlyOneOf: imageKeys,
+				AtLeastOneOf: imageKeys,
+				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
+					return new == ""
+				},
 			},
 			"image_id": {
 				Type:         schema.TypeInt,
 				ForceNew:     false,
 				Optional:     true,
-				ExactlyOneOf: imageKeys,
+				AtLeastOneOf: imageKeys,
+				Computed:     true,
 			},
 			"password": {
-				Type:         schema.TypeString,
-				ForceNew:     false,
-				Sensitive:    true,
-				Optional:     true,
-				ExactlyOneOf: credentialKeys,
+				Type:          schema.TypeString,
+				ForceNew:      false,
+				Sensitive:     true,
+				Optional:      true,
+				ConflictsWith: []string{"ssh_key_id", "ssh_key"},
 			},
 			"ssh_key_id": {
-				Type:         schema.TypeInt,
-				ForceNew:     false,
-				Optional:     true,
-				ExactlyOneOf: credentialKeys,
+				Type:          schema.TypeInt,
+				ForceNew:      false,
+				Optional:      true,
+				ConflictsWith: []string{"password", "ssh_key"},
 			},
 			"ssh_key": {
-				Type:         schema.TypeString,
-				ForceNew:     false,
-				Optional:     true,
-				ExactlyOneOf: credentialKeys,
+				Type:          schema.TypeString,
+				ForceNew:      false,
+				Optional:      true,
+				ConflictsWith: []string{"password", "ssh_key_id"},
 			},
 			"cloud_config": {
 				Type:     schema.TypeString,
@@ -156,6 +163,8 @@ func resourceServer() *schema.Resource {
 				Type:     schema.TypeString,
 				Computed: true,
 			},
+			"ipv4_addresses": ipAddressesSchema(),
+			"ipv6_addresses": ipAddressesSchema(),
 			"params": {
 				Type:        schema.TypeString,
 				Optional:    true,
@@ -163,18 +172,28 @@ func resourceServer() *schema.Resource {
 			},
 		},
 		CustomizeDiff: customdiff.Sequence(
+			computeLocationImageIDs,
 			customdiff.ComputedIf("primary_ipv4", func(_ context.Context, d *schema.ResourceDiff, meta interface{}) bool {
-				return d.HasChange("location_id") || d.HasChange("image") || d.HasChange("image_id") || d.HasChange("hostname")
+				return rebuildsServer(d)
 			}),
 			customdiff.ComputedIf("primary_ipv6", func(_ context.Context, d *schema.ResourceDiff, meta interface{}) bool {
-				return d.HasChange("location_id") || d.HasChange("image") || d.HasChange("image_id") || d.HasChange("hostname")
+				return rebuildsServer(d)
+			}),
+			customdiff.ComputedIf("ipv4_addresses", func(_ context.Context, d *schema.ResourceDiff, meta interface{}) bool {
+				return rebuildsServer(d)
+			}),
+			customdiff.ComputedIf("ipv6_addresses", func(_ context.Context, d *schema.ResourceDiff, meta interface{}) bool {
+				return rebuildsServer(d)
 			}),
+			validatePlanLocation,
+			validateImage,
+			validateBuildArguments,
 		),
 	}
 }
 
 func resourceServerCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*gona.Client)
+	c := m.(apiClient)
 
 	locationId, imageId, diags := getParams(d, c)
 	if diags != nil {
@@ -186,7 +205,7 @@ func resourceServerCreate(ctx context.Context, d *schema.ResourceData, m interfa
 		Plan:                     d.Get("plan").(string),
 		Location:                 locationId,
 		Image:                    imageId,
-		FQDN:                     d.Get("hostname").(string),
+		FQDN:                     asciiHostname(d.Get("hostname").(string)),
 		SSHKey:                   d.Get("ssh_key").(string),
 		SSHKeyID:                 d.Get("ssh_key_id").(int),
 		Password:                 d.Get("password").(string),
@@ -232,6 +251,8 @@ func resourceServerCreate(ctx context.Context, d *schema.ResourceData, m interfa
 
 	d.SetId(strconv.Itoa(s.ServerID))
 	d.Set("params", req.Params) // Store params in the state file
+	d.Set("location_id", locationId)
+	d.Set("image_id", imageId)
 
 	if _, err := wait4Status(s.ServerID, "RUNNING", c); err != nil {
 		return err
@@ -244,11 +265,18 @@ func resourceServerCreate(ctx context.Context, d *schema.ResourceData, m interfa
 	setValue("primary_ipv4", server.PrimaryIPv4, d, &diags)
 	setValue("primary_ipv6", server.PrimaryIPv6, d, &diags)
 
+	ips, err := c.GetIPs(s.ServerID)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	setValue("ipv4_addresses", flattenIPs(ips.IPv4, gona.IPv4), d, &diags)
+	setValue("ipv6_addresses", flattenIPs(ips.IPv6, gona.IPv6), d, &diags)
+
 	return nil
 }
 
 func resourceServerRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*gona.Client)
+	c := m.(apiClient)
 
 	id, err := strconv.Atoi(d.Id())
 	if err != nil {
@@ -262,38 +290,57 @@ func resourceServerRead(ctx context.Context, d *schema.ResourceData, m interface
 
 	var diags diag.Diagnostics
 
+	// The names are only filled in when the IDs aren't in state yet, i.e.
+	// on import, so that configurations using just the IDs plan cleanly.
+	_, exists_location_id := d.GetOk("location_id")
+	_, exists_location := d.GetOk("location")
+	_, exists_image_id := d.GetOk("image_id")
+	_, exists_image := d.GetOk("image")
+
 	if server.Installed == 0 {
 		setValue("hostname", "", d, &diags)
-		updateValue("image_id", 0, d, &diags)
+		setValue("image_id", 0, d, &diags)
 		updateValue("image", "", d, &diags)
 	} else {
 		setValue("hostname", server.Name, d, &diags)
-		updateValue("image_id", server.OSID, d, &diags)
-		updateValue("image", server.OS, d, &diags)
+		setValue("image_id", server.OSID, d, &diags)
+		if exists_image || !exists_image_id {
+			setValue("image", server.OS, d, &diags)
+		}
 	}
 	setValue("plan", server.Package, d, &diags)
-	updateValue("location_id", server.LocationID, d, &diags)
-	updateValue("location", strings.Fields(server.Location)[0], d, &diags)
-
-	_, exists_location_id := d.GetOk("location_id")
-	_, exists_location := d.GetOk("location")
-	if !exists_location_id && !exists_location {
-		setValue("location", strings.Fields(server.Location)[0], d, &diags)
+	setValue("location_id", server.LocationID, d, &diags)
+	if exists_location || !exists_location_id {
+		setValue("location", locationCode(server.Location), d, &diags)
 	}
 
-	_, exists_image_id := d.GetOk("image_id")
-	_, exists_image := d.GetOk("image")
-	if !exists_image_id && !exists_image {
-		setValue("image", server.OS, d, &diags)
+	_, exists_contract_id := d.GetOk("package_billing_contract_id")
+	_, exists_opt_in := d.GetOk("package_billing_opt_in")
+	if !exists_contract_id && !exists_opt_in {
+		if server.PackageBilling != "" {
+			setValue("package_billing", server.PackageBilling, d, &diags)
+		}
+		if server.PackageBillingContractId != "" {
+			setValue("package_billing_contract_id", server.PackageBillingContractId, d, &diags)
+		} else if server.PackageBilling == "package" {
+			setValue("package_billing_opt_in", "yes", d, &diags)
+		}
 	}
 	setValue("primary_ipv4", server.PrimaryIPv4, d, &diags)
 	setValue("primary_ipv6", server.PrimaryIPv6, d, &diags)
 
+	ips, err := c.GetIPs(id)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	setValue("ipv4_addresses", flattenIPs(ips.IPv4, gona.IPv4), d, &diags)
+	setValue("ipv6_addresses", flattenIPs(ips.IPv6, gona.IPv6), d, &diags)
+
 	return diags
 }
 
 func resourceServerUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*gona.Client)
+	c := m.(apiClient)
 	// Rebuild on these property changes
 	if d.HasChange("location") || d.HasChange("location_id") || d.HasChange("image") || d.HasChange("image_id") || d.HasChange("hostname") || d.HasChange("params") {
 		id, err := strconv.Atoi(d.Id())
@@ -325,17 +372,7 @@ func resourceServerUpdate(ctx context.Context, d *schema.ResourceData, m interfa
 			oldLoc := oldLoc_r.(string)
 			setValue("location_id", 0, d, &diag.Diagnostics{})
 			if oldLoc != "" {
-				var diags diag.Diagnostics
 				unlinkRequired = true
-				if len(diags) > 0 {
-					return diags
-				}
-				if unlinkRequired {
-					err = c.UnlinkServer(id)
-					if err != nil {
-						return diag.FromErr(err)
-					}
-				}
 			}
 		}
 
@@ -345,15 +382,20 @@ func resourceServerUpdate(ctx context.Context, d *schema.ResourceData, m interfa
 			if oldLoc != 0 {
 				unlinkRequired = true
 			}
+		}
 
-			if unlinkRequired {
-				err = c.UnlinkServer(id)
-				if err != nil {
-					return diag.FromErr(err)
-				}
+		// Read stores both forms, so only unlink once when both change.
+		if unlinkRequired {
+			err = c.UnlinkServer(id)
+			if err != nil {
+				return diag.FromErr(err)
 			}
 		}
 
+		if d.HasChange("image") {
+			setValue("image_id", 0, d, &diag.Diagnostics{})
+		}
+
 		// Get correct build params
 		locationId, imageId, diags := getParams(d, c)
 		if diags != nil {
@@ -363,7 +405,7 @@ func resourceServerUpdate(ctx context.Context, d *schema.ResourceData, m interfa
 			Plan:                     d.Get("plan").(string),
 			Location:                 locationId,
 			Image:                    imageId,
-			FQDN:                     d.Get("hostname").(string),
+			FQDN:                     asciiHostname(d.Get("hostname").(string)),
 			SSHKey:                   d.Get("ssh_key").(string),
 			SSHKeyID:                 d.Get("ssh_key_id").(int),
 			Password:                 d.Get("password").(string),
@@ -398,7 +440,7 @@ func resourceServerUpdate(ctx context.Context, d *schema.ResourceData, m interfa
 }
 
 func resourceServerDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*gona.Client)
+	c := m.(apiClient)
 
 	id, err := strconv.Atoi(d.Id())
 	if err != nil {
@@ -417,7 +459,46 @@ func resourceServerDelete(ctx context.Context, d *schema.ResourceData, m interfa
 	return nil
 }
 
-func wait4Status(serverId int, status string, client *gona.Client) (server gona.Server, d diag.Diagnostics) {
+// resourceServerImport accepts a numeric server ID, or hostname:<fqdn> with
+// an optional /<location> to tell apart servers sharing a hostname. Read then
+// fills in location, image and the billing arguments, as none are set yet.
+func resourceServerImport(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	c := m.(apiClient)
+
+	var server gona.Server
+	if lookup, ok := strings.CutPrefix(d.Id(), "hostname:"); ok {
+		hostname, location, _ := strings.Cut(lookup, "/")
+		hostname, err := hostnameToASCII(hostname)
+		if err != nil {
+			return nil, err
+		}
+
+		server, err = getServerByHostname(hostname, location, c)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		id, err := strconv.Atoi(d.Id())
+		if err != nil || id <= 0 {
+			return nil, fmt.Errorf("Invalid server import ID %q, expected a server ID or hostname:<fqdn>[/<location>]", d.Id())
+		}
+
+		server, err = c.GetServer(id)
+		if err != nil {
+			return nil, fmt.Errorf("Could not import server %d: %w", id, err)
+		}
+		if server.ID != id {
+			// Unknown packages come back as an empty server.
+			return nil, fmt.Errorf("Server %d doesn't exist", id)
+		}
+	}
+
+	d.SetId(strconv.Itoa(server.ID))
+
+	return []*schema.ResourceData{d}, nil
+}
+
+func wait4Status(serverId int, status string, client apiClient) (server gona.Server, d diag.Diagnostics) {
 	for i := 0; i < tries; i++ {
 		server, err := client.GetServer(serverId)
 
@@ -438,13 +519,223 @@ func wait4Status(serverId int, status string, client *gona.Client) (server gona.
 			return server, nil
 		}
 
-		time.Sleep(intervalSec * time.Second)
+		time.Sleep(pollInterval)
 	}
 
 	return server, diag.Errorf("Timeout of waiting the server to obtain %q status", status)
 }
 
-func getParams(d *schema.ResourceData, client *gona.Client) (int, int, diag.Diagnostics) {
+// computeLocationImageIDs marks location_id and image_id as unknown when only
+// location or image changes, as Read stores both forms. Changing one form
+// while configuring the old value of the other is rejected, as the server
+// would be rebuilt on every apply.
+func computeLocationImageIDs(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if d.Id() == "" {
+		return nil
+	}
+
+	changed := map[string]bool{
+		"location": d.HasChange("location") && locationChanged(d),
+		"image":    d.HasChange("image"),
+	}
+	for _, keys := range [][]string{locationKeys, imageKeys} {
+		name, id := keys[0], keys[1]
+		switch {
+		case changed[name] && !d.HasChange(id):
+			if configured(d, id) {
+				return fmt.Errorf("`%s` changed but `%s` didn't, change both or remove `%s`", name, id, id)
+			}
+			if err := d.SetNewComputed(id); err != nil {
+				return err
+			}
+		case d.HasChange(id) && !changed[name] && configured(d, name):
+			return fmt.Errorf("`%s` changed but `%s` didn't, change both or remove `%s`", id, name, name)
+		}
+	}
+	return nil
+}
+
+// locationChanged compares locations by their code, e.g. "sjc" and "SJC"
+// are the same location.
+func locationChanged(d *schema.ResourceDiff) bool {
+	o, n := d.GetChange("location")
+	return n.(string) != "" && locationCode(o.(string)) != locationCode(n.(string))
+}
+
+// configured reports whether key is set in the configuration rather than
+// only in state. Without the raw configuration, e.g. in unit tests, it
+// reports false.
+func configured(d *schema.ResourceDiff, key string) bool {
+	raw := d.GetRawConfig()
+	if raw.IsNull() || !raw.IsKnown() {
+		return false
+	}
+	return !raw.GetAttr(key).IsNull()
+}
+
+// rebuildsServer reports whether the diff rebuilds the server, which gives it
+// new IP addresses.
+func rebuildsServer(d *schema.ResourceDiff) bool {
+	return d.HasChange("location_id") || d.HasChange("image") || d.HasChange("image_id") || hostnameChanged(d)
+}
+
+// hostnameChanged compares hostnames by their ASCII form, as the API stores
+// internationalized hostnames in punycode.
+func hostnameChanged(d *schema.ResourceDiff) bool {
+	if !d.HasChange("hostname") {
+		return false
+	}
+	o, n := d.GetChange("hostname")
+	a, errOld := hostnameToASCII(o.(string))
+	b, errNew := hostnameToASCII(n.(string))
+	return errOld != nil || errNew != nil || a != b
+}
+
+// validateBuildArguments requires credentials whenever the server will be
+// built, and billing arguments when it is bought. They can't be read back from
+// the API, so unlike location and image they are optional in the schema to
+// let imported servers be configured without them.
+func validateBuildArguments(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	// HasChange sees the configured value before DiffSuppressFunc, so compare
+	// locations and hostnames the way their diffs do.
+	building := d.Id() == "" || d.HasChanges("plan", "params") || d.HasChange("location") && locationChanged(d) || rebuildsServer(d)
+	if building && !anySet(d, credentialKeys) {
+		return fmt.Errorf("one of `%s` must be specified to build the server", strings.Join(credentialKeys, ","))
+	}
+	if d.Id() == "" && !anySet(d, billingKeys) {
+		return fmt.Errorf("one of `%s` must be specified", strings.Join(billingKeys, ","))
+	}
+	return nil
+}
+
+// anySet reports whether any of keys is set, counting values that won't be
+// known until apply.
+func anySet(d *schema.ResourceDiff, keys []string) bool {
+	for _, key := range keys {
+		if _, ok := d.GetOk(key); ok || !d.NewValueKnown(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// validatePlanLocation rejects plans and locations that the API doesn't know
+// about, plans that are out of stock and locations that are disabled. The API
+// doesn't report stock per location, so the two are checked separately.
+func validatePlanLocation(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	c, ok := m.(apiClient)
+	if !ok || !d.NewValueKnown("plan") || !d.NewValueKnown("location") {
+		return nil
+	}
+	// location_id is computed, so it is only known up front when configured
+	if d.Get("location").(string) == "" && !d.NewValueKnown("location_id") {
+		return nil
+	}
+	if d.Id() != "" && !d.HasChanges("plan", "location", "location_id") {
+		return nil
+	}
+
+	// Servers on legacy or contract plans that are no longer listed can still
+	// move, so the plan is only checked when it's chosen.
+	if d.Id() == "" || d.HasChange("plan") {
+		if err := validatePlan(c, d.Get("plan").(string)); err != nil {
+			return err
+		}
+	}
+
+	// location_id is computed from location, so prefer the name when it's set
+	// and only check that both match when they are configured together.
+	requestLocation := d.Get("location").(string)
+	locationId := d.Get("location_id").(int)
+	matchId := 0
+	if requestLocation != "" {
+		if d.Id() == "" || d.HasChange("location_id") {
+			matchId = locationId
+		}
+		locationId = 0
+	} else if locationId == 0 {
+		return nil
+	}
+
+	locations, err := c.GetLocations()
+	if err != nil {
+		return err
+	}
+
+	for _, location := range locations {
+		if locationId != 0 && location.ID != locationId {
+			continue
+		}
+		if locationId == 0 && locationCode(location.Name) != locationCode(requestLocation) {
+			continue
+		}
+		if matchId != 0 && location.ID != matchId {
+			return fmt.Errorf("Location %q doesn't match location_id %d", requestLocation, matchId)
+		}
+		if location.Disabled != 0 {
+			return fmt.Errorf("Location %q is disabled and doesn't accept new servers", location.Name)
+		}
+		return nil
+	}
+
+	if locationId != 0 {
+		return fmt.Errorf("Provided location_id %d doesn't exist", locationId)
+	}
+	return fmt.Errorf("Provided location %q doesn't exist", requestLocation)
+}
+
+func validatePlan(c apiClient, plan string) error {
+	plans, err := c.GetPlans()
+	if err != nil {
+		return err
+	}
+
+	for _, p := range plans {
+		if strings.EqualFold(p.Name, plan) {
+			if !planAvailable(p) {
+				return fmt.Errorf("Plan %q is currently out of stock", plan)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("Provided plan %q doesn't exist", plan)
+}
+
+// validateImage checks that image and image_id name the same image when both
+// are configured.
+func validateImage(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	c, ok := m.(apiClient)
+	if !ok || !d.NewValueKnown("image") || !d.NewValueKnown("image_id") {
+		return nil
+	}
+	if d.Id() != "" && !(d.HasChange("image") && d.HasChange("image_id")) {
+		return nil
+	}
+
+	image := d.Get("image").(string)
+	imageId := d.Get("image_id").(int)
+	if image == "" || imageId == 0 {
+		return nil
+	}
+
+	oss, err := c.GetOSs()
+	if err != nil {
+		return err
+	}
+
+	for _, os := range oss {
+		if os.ID == imageId {
+			if os.Os != image {
+				return fmt.Errorf("Image %q doesn't match image_id %d (%q)", image, imageId, os.Os)
+			}
+			return nil
+		}
+	}
+
+	return fmt.Errorf("Provided image_id %d doesn't exist", imageId)
+}
+
+func getParams(d *schema.ResourceData, client apiClient) (int, int, diag.Diagnostics) {
 	var diags diag.Diagnostics
 	locationId, ld := getLocation(d, client)
 	if ld != nil {
@@ -463,14 +754,14 @@ func getParams(d *schema.ResourceData, client *gona.Client) (int, int, diag.Diag
 	return locationId, imageId.(int), diags
 }
 
-func getLocation(d *schema.ResourceData, client *gona.Client) (int, *diag.Diagnostic) {
+func getLocation(d *schema.ResourceData, client apiClient) (int, *diag.Diagnostic) {
 	locationId, exists := d.GetOk("location_id")
 	if exists {
 		return locationId.(int), nil
 	}
 
 	requestLocation := d.Get("location").(string)
-	if requestLocation == "" {
+	if locationCode(requestLocation) == "" {
 		return 0, &diag.Errorf("Please provide a location or location_id")[0]
 	}
 
@@ -483,15 +774,15 @@ func getLocation(d *schema.ResourceData, client *gona.Client) (int, *diag.Diagno
 		if location.Name == requestLocation {
 			return location.ID, nil
 		}
-		if strings.EqualFold(strings.Fields(location.Name)[0], strings.Fields(requestLocation)[0]) {
+		if locationCode(location.Name) == locationCode(requestLocation) {
 			return location.ID, nil
 		}
 	}
 
-	return 0, &diag.Errorf("Provided location %q doesn't exist", locationId)[0]
+	return 0, &diag.Errorf("Provided location %q doesn't exist", requestLocation)[0]
 }
 
-func getImageByName(name string, client *gona.Client) (*gona.OS, *diag.Diagnostic) {
+func getImageByName(name string, client apiClient) (*gona.OS, *diag.Diagnostic) {
 	oss, err := client.GetOSs()
 	if err != nil {
 		return nil, &diag.FromErr(err)[0]
@@ -0,0 +1,84 @@
+package netactuate
+
+import (
+	"context"
+	"log"
+	"strconv"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// resourceServerV0 is the server schema before versioning: location could be
+// stored in any case, location_id was only set when configured, and the IP
+// address lists didn't exist yet.
+func resourceServerV0() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"hostname":                    {Type: schema.TypeString, Required: true},
+			"plan":                        {Type: schema.TypeString, Required: true},
+			"package_billing":             {Type: schema.TypeString, Optional: true},
+			"package_billing_opt_in":      {Type: schema.TypeString, Optional: true},
+			"package_billing_contract_id": {Type: schema.TypeString, Optional: true},
+			"location":                    {Type: schema.TypeString, Optional: true},
+			"location_id":                 {Type: schema.TypeInt, Optional: true, Computed: true},
+			"image":                       {Type: schema.TypeString, Optional: true},
+			"image_id":                    {Type: schema.TypeInt, Optional: true},
+			"password":                    {Type: schema.TypeString, Optional: true, Sensitive: true},
+			"ssh_key_id":                  {Type: schema.TypeInt, Optional: true},
+			"ssh_key":                     {Type: schema.TypeString, Optional: true},
+			"cloud_config":                {Type: schema.TypeString, Optional: true},
+			"user_data":                   {Type: schema.TypeString, Optional: true},
+			"user_data_base64":            {Type: schema.TypeString, Optional: true},
+			"primary_ipv4":                {Type: schema.TypeString, Computed: true},
+			"primary_ipv6":                {Type: schema.TypeString, Computed: true},
+			"params":                      {Type: schema.TypeString, Optional: true},
+		},
+	}
+}
+
+func resourceServerV0Type() cty.Type {
+	return resourceServerV0().CoreConfigSchema().ImpliedType()
+}
+
+// resourceServerStateUpgradeV0 normalises location to the code that Read
+// stores, e.g. "SJC" for "sjc", and fills in location_id from the API so that
+// later changes can be compared by ID. The lookup is skipped when the provider
+// isn't configured, the server no longer exists or the API call fails; Read
+// handles all three.
+func resourceServerStateUpgradeV0(ctx context.Context, rawState map[string]interface{}, meta interface{}) (map[string]interface{}, error) {
+	if rawState == nil {
+		return rawState, nil
+	}
+
+	if location, ok := rawState["location"].(string); ok && location != "" {
+		rawState["location"] = locationCode(location)
+	}
+
+	if locationID, _ := rawState["location_id"].(float64); locationID != 0 {
+		return rawState, nil
+	}
+
+	c, ok := meta.(apiClient)
+	if !ok {
+		return rawState, nil
+	}
+	idValue, _ := rawState["id"].(string)
+	id, err := strconv.Atoi(idValue)
+	if err != nil {
+		return rawState, nil
+	}
+
+	// A failed lookup would fail the plan for every server still in v0 state,
+	// so leave location_id for the next Read instead.
+	server, err := c.GetServer(id)
+	if err != nil {
+		log.Printf("[WARN] Could not look up location_id of server %d: %s", id, err)
+		return rawState, nil
+	}
+	if server.ID == id && server.LocationID != 0 {
+		rawState["location_id"] = server.LocationID
+	}
+
+	return rawState, nil
+}
@@ -0,0 +1,173 @@
+package netactuate
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestResourceServerV0Type(t *testing.T) {
+	// Terraform decodes the stored state with this type before upgrading it,
+	// so it must list every attribute the unversioned schema had.
+	for _, name := range []string{"params", "location_id", "image_id", "primary_ipv4", "primary_ipv6"} {
+		if !resourceServerV0Type().HasAttribute(name) {
+			t.Errorf("expected the v0 type to have %q", name)
+		}
+	}
+}
+
+func TestResourceServerStateUpgradeV0(t *testing.T) {
+	m := newMockAPI()
+	defer m.Close()
+
+	sjc := strconv.Itoa(m.addServer("web1.example.com", 1))
+	ams := strconv.Itoa(m.addServer("web2.example.com", 2))
+
+	tests := []struct {
+		name      string
+		rawState  map[string]interface{}
+		meta      interface{}
+		want      map[string]interface{}
+		wantCalls int
+	}{
+		{
+			name:     "lowercase location",
+			rawState: map[string]interface{}{"id": sjc, "location": "sjc"},
+			meta:     testClient(m),
+			want:     map[string]interface{}{"id": sjc, "location": "SJC", "location_id": 1},
+		},
+		{
+			name:     "full location name",
+			rawState: map[string]interface{}{"id": ams, "location": "ams - Amsterdam, NL", "location_id": nil},
+			meta:     testClient(m),
+			want:     map[string]interface{}{"id": ams, "location": "AMS", "location_id": 2},
+		},
+		{
+			name:     "location_id only",
+			rawState: map[string]interface{}{"id": ams, "location_id": float64(2)},
+			meta:     &fakeClient{},
+			want:     map[string]interface{}{"id": ams, "location_id": float64(2)},
+		},
+		{
+			name:     "location_id already set",
+			rawState: map[string]interface{}{"id": sjc, "location": "sjc", "location_id": float64(1)},
+			meta:     &fakeClient{},
+			want:     map[string]interface{}{"id": sjc, "location": "SJC", "location_id": float64(1)},
+		},
+		{
+			name:     "params",
+			rawState: map[string]interface{}{"id": sjc, "location": "SJC", "location_id": float64(1), "params": `{"foo":"bar"}`},
+			meta:     &fakeClient{},
+			want:     map[string]interface{}{"id": sjc, "location": "SJC", "location_id": float64(1), "params": `{"foo":"bar"}`},
+		},
+		{
+			name:     "provider not configured",
+			rawState: map[string]interface{}{"id": sjc, "location": "sjc"},
+			want:     map[string]interface{}{"id": sjc, "location": "SJC"},
+		},
+		{
+			name:     "server no longer exists",
+			rawState: map[string]interface{}{"id": "999999", "location": "sjc"},
+			meta:     testClient(m),
+			want:     map[string]interface{}{"id": "999999", "location": "SJC"},
+		},
+		{
+			name:      "API error",
+			rawState:  map[string]interface{}{"id": sjc, "location": "sjc"},
+			meta:      &fakeClient{responses: failures(errors.New("got an error response"), 1)},
+			want:      map[string]interface{}{"id": sjc, "location": "SJC"},
+			wantCalls: 1,
+		},
+		{
+			name:     "nil state",
+			rawState: nil,
+			meta:     testClient(m),
+			want:     nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resourceServerStateUpgradeV0(context.Background(), tt.rawState, tt.meta)
+
+			if c, ok := tt.meta.(*fakeClient); ok && c.calls != tt.wantCalls {
+				t.Errorf("expected %d API calls, got %d", tt.wantCalls, c.calls)
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestResourceServerStateUpgradeV0NoChanges checks that a server stored by an
+// older provider plans without changes after the upgrade, whichever way its
+// location is configured.
+func TestResourceServerStateUpgradeV0NoChanges(t *testing.T) {
+	m := newMockAPI()
+	defer m.Close()
+
+	id := strconv.Itoa(m.addServer("web1.example.com", 1))
+	v0 := map[string]interface{}{
+		"id":                          id,
+		"hostname":                    "web1.example.com",
+		"plan":                        "VR1x1x25",
+		"location":                    "sjc",
+		"image":                       "Ubuntu 22.04 (20221110)",
+		"ssh_key":                     testSSHKey,
+		"package_billing":             "usage",
+		"package_billing_contract_id": "1234",
+	}
+
+	upgraded, err := resourceServerStateUpgradeV0(context.Background(), v0, testClient(m))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The address lists didn't exist in v0; the refresh before planning fills
+	// them in.
+	state := &terraform.InstanceState{ID: id, Attributes: map[string]string{
+		"ipv4_addresses.#": "0",
+		"ipv6_addresses.#": "0",
+	}}
+	for key, value := range upgraded {
+		switch v := value.(type) {
+		case string:
+			state.Attributes[key] = v
+		case int:
+			state.Attributes[key] = strconv.Itoa(v)
+		}
+	}
+
+	for _, location := range []map[string]interface{}{
+		{"location": "sjc"},
+		{"location": "SJC"},
+		{"location_id": 1},
+	} {
+		raw := map[string]interface{}{
+			"hostname":                    "web1.example.com",
+			"plan":                        "VR1x1x25",
+			"image":                       "Ubuntu 22.04 (20221110)",
+			"ssh_key":                     testSSHKey,
+			"package_billing_contract_id": "1234",
+		}
+		for k, v := range location {
+			raw[k] = v
+		}
+
+		diff, err := resourceServer().Diff(context.Background(), state, terraform.NewResourceConfigRaw(raw), nil)
+		if err != nil {
+			t.Fatalf("%v: %v", location, err)
+		}
+		if !diff.Empty() {
+			t.Errorf("%v: expected no changes, got %v", location, diff.Attributes)
+		}
+	}
+}
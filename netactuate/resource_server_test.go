@@ -0,0 +1,962 @@
+package netactuate
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/go-cty/cty/gocty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/netactuate/gona/gona"
+)
+
+var errInvalidMbpkgid = errors.New("mbpkgid must be a valid mbpkgid")
+
+func init() {
+	pollInterval = time.Millisecond
+}
+
+func TestServerLifecycle(t *testing.T) {
+	m := newMockAPI()
+	defer m.Close()
+
+	ctx := context.Background()
+	c := testClient(m)
+
+	d := schema.TestResourceDataRaw(t, resourceServer().Schema, map[string]interface{}{
+		"hostname":                    "web1.example.com",
+		"plan":                        "VR1x1x25",
+		"location":                    "SJC",
+		"image":                       "Ubuntu 22.04 (20221110)",
+		"ssh_key":                     "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIGb0 test",
+		"package_billing_contract_id": "1234",
+	})
+
+	if diags := resourceServerCreate(ctx, d, c); diags.HasError() {
+		t.Fatalf("create: %v", diags)
+	}
+	if d.Id() == "" {
+		t.Fatal("expected an ID after create")
+	}
+	if diags := resourceServerRead(ctx, d, c); diags.HasError() {
+		t.Fatalf("read: %v", diags)
+	}
+
+	for key, want := range map[string]interface{}{
+		"hostname":                 "web1.example.com",
+		"location":                 "SJC",
+		"plan":                     "VR1x1x25",
+		"ipv4_addresses.0.primary": true,
+	} {
+		if got := d.Get(key); got != want {
+			t.Errorf("%s: got %v, want %v", key, got, want)
+		}
+	}
+
+	if diags := resourceServerDelete(ctx, d, c); diags.HasError() {
+		t.Fatalf("delete: %v", diags)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.servers) != 0 {
+		t.Fatalf("expected no servers after delete, got %v", m.listServers())
+	}
+}
+
+func TestWait4Status(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    string
+		responses []fakeResponse
+		wantErr   string
+		wantCalls int
+	}{
+		{
+			name:      "already running",
+			status:    "RUNNING",
+			responses: status("RUNNING", 1),
+			wantCalls: 1,
+		},
+		{
+			name:      "building then running",
+			status:    "RUNNING",
+			responses: script(status("BUILDING", 3), status("RUNNING", 1)),
+			wantCalls: 4,
+		},
+		{
+			name:      "blank status counts as terminated",
+			status:    "TERMINATED",
+			responses: script(status("DELETING", 2), status("", 1)),
+			wantCalls: 3,
+		},
+		{
+			name:      "blank status does not count as running",
+			status:    "RUNNING",
+			responses: script(status("", 2), status("RUNNING", 1)),
+			wantCalls: 3,
+		},
+		{
+			name:      "five initial errors are tolerated",
+			status:    "RUNNING",
+			responses: script(failures(errInvalidMbpkgid, 5), status("RUNNING", 1)),
+			wantCalls: 6,
+		},
+		{
+			name:      "sixth consecutive error fails",
+			status:    "RUNNING",
+			responses: failures(errInvalidMbpkgid, 6),
+			wantErr:   errInvalidMbpkgid.Error(),
+			wantCalls: 6,
+		},
+		{
+			name:      "late error fails immediately",
+			status:    "RUNNING",
+			responses: script(status("BUILDING", 7), failures(errInvalidMbpkgid, 1)),
+			wantErr:   errInvalidMbpkgid.Error(),
+			wantCalls: 8,
+		},
+		{
+			name:      "errors while terminating are tolerated",
+			status:    "TERMINATED",
+			responses: script(failures(errInvalidMbpkgid, 2), status("TERMINATED", 1)),
+			wantCalls: 3,
+		},
+		{
+			name:      "slow responses",
+			status:    "RUNNING",
+			responses: []fakeResponse{{server: gona.Server{ServerStatus: "RUNNING"}, delay: 20 * time.Millisecond}},
+			wantCalls: 1,
+		},
+		{
+			name:      "timeout",
+			status:    "RUNNING",
+			responses: status("BUILDING", 1),
+			wantErr:   `Timeout of waiting the server to obtain "RUNNING" status`,
+			wantCalls: tries,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &fakeClient{responses: tt.responses}
+
+			server, diags := wait4Status(1234, tt.status, c)
+
+			if tt.wantErr != "" {
+				if !diags.HasError() || !strings.Contains(diags[0].Summary, tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, diags)
+				}
+			} else {
+				if diags.HasError() {
+					t.Fatalf("unexpected error: %v", diags)
+				}
+				if server.ID != 1234 {
+					t.Errorf("expected server 1234, got %d", server.ID)
+				}
+			}
+			if c.calls != tt.wantCalls {
+				t.Errorf("expected %d calls, got %d", tt.wantCalls, c.calls)
+			}
+		})
+	}
+}
+
+func TestGetLocation(t *testing.T) {
+	locations := []gona.Location{
+		{ID: 1, Name: "SJC - San Jose, CA"},
+		{ID: 2, Name: "AMS"},
+		{ID: 3, Name: ""},
+		{ID: 4, Name: "   "},
+		{ID: 5, Name: "Frankfurt, DE"},
+	}
+
+	tests := []struct {
+		name         string
+		raw          map[string]interface{}
+		locations    []gona.Location
+		locationsErr error
+		wantID       int
+		wantErr      string
+		wantAPICalls int
+	}{
+		{
+			name:         "location_id skips the API",
+			raw:          map[string]interface{}{"location_id": 42},
+			wantID:       42,
+			wantAPICalls: 0,
+		},
+		{
+			name:         "exact name",
+			raw:          map[string]interface{}{"location": "SJC - San Jose, CA"},
+			locations:    locations,
+			wantID:       1,
+			wantAPICalls: 1,
+		},
+		{
+			name:         "first word, any case",
+			raw:          map[string]interface{}{"location": "sjc"},
+			locations:    locations,
+			wantID:       1,
+			wantAPICalls: 1,
+		},
+		{
+			name:         "first word of the request",
+			raw:          map[string]interface{}{"location": "AMS - Amsterdam"},
+			locations:    locations,
+			wantID:       2,
+			wantAPICalls: 1,
+		},
+		{
+			name:         "first word with punctuation",
+			raw:          map[string]interface{}{"location": "frankfurt,"},
+			locations:    locations,
+			wantID:       5,
+			wantAPICalls: 1,
+		},
+		{
+			name:         "unknown location names the request",
+			raw:          map[string]interface{}{"location": "XYZ"},
+			locations:    locations,
+			wantErr:      `Provided location "XYZ" doesn't exist`,
+			wantAPICalls: 1,
+		},
+		{
+			name:         "blank location",
+			raw:          map[string]interface{}{"location": "  "},
+			locations:    locations,
+			wantErr:      "Please provide a location or location_id",
+			wantAPICalls: 0,
+		},
+		{
+			name:         "empty location list",
+			raw:          map[string]interface{}{"location": "SJC"},
+			wantErr:      `Provided location "SJC" doesn't exist`,
+			wantAPICalls: 1,
+		},
+		{
+			name:         "API error",
+			raw:          map[string]interface{}{"location": "SJC"},
+			locationsErr: errors.New("got an error response"),
+			wantErr:      "got an error response",
+			wantAPICalls: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &fakeClient{locations: tt.locations, locationsErr: tt.locationsErr}
+			d := schema.TestResourceDataRaw(t, resourceServer().Schema, tt.raw)
+
+			id, diag := getLocation(d, c)
+
+			if tt.wantErr != "" {
+				if diag == nil || !strings.Contains(diag.Summary, tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, diag)
+				}
+			} else {
+				if diag != nil {
+					t.Fatalf("unexpected error: %v", diag.Summary)
+				}
+				if id != tt.wantID {
+					t.Errorf("expected location %d, got %d", tt.wantID, id)
+				}
+			}
+			if c.apiCalls != tt.wantAPICalls {
+				t.Errorf("expected %d API calls, got %d", tt.wantAPICalls, c.apiCalls)
+			}
+		})
+	}
+}
+
+func TestGetImageByName(t *testing.T) {
+	oss := []gona.OS{
+		{ID: 10, Os: "Ubuntu 22.04 (20221110)"},
+		{ID: 11, Os: "Debian 12 (20230612)"},
+	}
+
+	tests := []struct {
+		name    string
+		image   string
+		oss     []gona.OS
+		ossErr  error
+		wantID  int
+		wantErr string
+	}{
+		{name: "exact match", image: "Debian 12 (20230612)", oss: oss, wantID: 11},
+		{name: "names are case sensitive", image: "debian 12 (20230612)", oss: oss, wantErr: "doesn't exist"},
+		{name: "partial names don't match", image: "Ubuntu 22.04", oss: oss, wantErr: `"Ubuntu 22.04" doesn't exist`},
+		{name: "empty image list", image: "Ubuntu 22.04 (20221110)", wantErr: "doesn't exist"},
+		{name: "API error", image: "Ubuntu 22.04 (20221110)", ossErr: errors.New("boom"), wantErr: "boom"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			image, diag := getImageByName(tt.image, &fakeClient{oss: tt.oss, ossErr: tt.ossErr})
+
+			if tt.wantErr != "" {
+				if diag == nil || !strings.Contains(diag.Summary, tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, diag)
+				}
+				return
+			}
+			if diag != nil {
+				t.Fatalf("unexpected error: %v", diag.Summary)
+			}
+			if image.ID != tt.wantID {
+				t.Errorf("expected image %d, got %d", tt.wantID, image.ID)
+			}
+		})
+	}
+}
+
+func TestValidatePlanLocation(t *testing.T) {
+	m := newMockAPI()
+	defer m.Close()
+
+	tests := []struct {
+		name    string
+		raw     map[string]interface{}
+		wantErr string
+	}{
+		{name: "location", raw: map[string]interface{}{"location": "SJC"}},
+		{name: "lowercase location", raw: map[string]interface{}{"location": "ams"}},
+		{name: "location_id", raw: map[string]interface{}{"location_id": 2}},
+		{name: "unknown location_id", raw: map[string]interface{}{"location_id": unknownValue}},
+		{name: "unknown plan", raw: map[string]interface{}{"location": "SJC", "plan": "NOPE"}, wantErr: `Provided plan "NOPE" doesn't exist`},
+		{name: "unknown location", raw: map[string]interface{}{"location": "XYZ"}, wantErr: `Provided location "XYZ" doesn't exist`},
+		{name: "unknown location id", raw: map[string]interface{}{"location_id": 99}, wantErr: "Provided location_id 99 doesn't exist"},
+		{name: "out of stock plan", raw: map[string]interface{}{"location": "SJC", "plan": "VR4x4x100"}, wantErr: `Plan "VR4x4x100" is currently out of stock`},
+		{name: "disabled location", raw: map[string]interface{}{"location": "LHR"}, wantErr: `Location "LHR - London, UK" is disabled`},
+		{name: "disabled location id", raw: map[string]interface{}{"location_id": 3}, wantErr: `Location "LHR - London, UK" is disabled`},
+		{name: "location and location_id", raw: map[string]interface{}{"location": "sjc", "location_id": 1}},
+		{name: "mismatched location_id", raw: map[string]interface{}{"location": "SJC", "location_id": 2}, wantErr: `Location "SJC" doesn't match location_id 2`},
+		{name: "image and image_id", raw: map[string]interface{}{"location": "SJC", "image_id": 10}},
+		{name: "mismatched image_id", raw: map[string]interface{}{"location": "SJC", "image_id": 11}, wantErr: `Image "Ubuntu 22.04 (20221110)" doesn't match image_id 11`},
+		{name: "unknown image_id", raw: map[string]interface{}{"location": "SJC", "image_id": 99}, wantErr: "Provided image_id 99 doesn't exist"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw := map[string]interface{}{
+				"hostname":                    "web1.example.com",
+				"plan":                        "VR1x1x25",
+				"image":                       "Ubuntu 22.04 (20221110)",
+				"ssh_key":                     testSSHKey,
+				"package_billing_contract_id": "1234",
+			}
+			for k, v := range tt.raw {
+				raw[k] = v
+			}
+
+			_, err := resourceServer().Diff(context.Background(), nil, terraform.NewResourceConfigRaw(raw), testClient(m))
+
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidatePlanLocationLegacyPlan(t *testing.T) {
+	m := newMockAPI()
+	defer m.Close()
+
+	ctx := context.Background()
+	c := testClient(m)
+	r := resourceServer()
+
+	// The server is on a plan that is no longer listed.
+	id := m.addServer("web1.example.com", 1)
+	m.mu.Lock()
+	m.servers[id].Package = "VR-LEGACY"
+	m.mu.Unlock()
+
+	d := r.TestResourceData()
+	d.SetId(strconv.Itoa(id))
+	d.Set("location", "SJC")
+	d.Set("image", "Ubuntu 22.04 (20221110)")
+	if diags := resourceServerRead(ctx, d, c); diags.HasError() {
+		t.Fatalf("read: %v", diags)
+	}
+
+	tests := []struct {
+		name    string
+		raw     map[string]interface{}
+		wantErr string
+	}{
+		{name: "move", raw: map[string]interface{}{"plan": "VR-LEGACY", "location": "AMS"}},
+		{name: "move to a disabled location", raw: map[string]interface{}{"plan": "VR-LEGACY", "location": "LHR"}, wantErr: "is disabled"},
+		{name: "change plan", raw: map[string]interface{}{"plan": "VR-OTHER", "location": "AMS"}, wantErr: `Provided plan "VR-OTHER" doesn't exist`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw := map[string]interface{}{
+				"hostname":                    "web1.example.com",
+				"image":                       "Ubuntu 22.04 (20221110)",
+				"ssh_key":                     testSSHKey,
+				"package_billing_contract_id": "1234",
+			}
+			for k, v := range tt.raw {
+				raw[k] = v
+			}
+
+			_, err := r.Diff(ctx, d.State(), terraform.NewResourceConfigRaw(raw), c)
+
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestServerImport(t *testing.T) {
+	m := newMockAPI()
+	defer m.Close()
+
+	web1 := m.addServer("web1.example.com", 1)
+	m.addServer("dup.example.com", 1)
+	dupAMS := m.addServer("dup.example.com", 2)
+	unicode := m.addServer("xn--bcher-kva.example.com", 2)
+
+	m.mu.Lock()
+	m.servers[web1].PackageBilling = "usage"
+	m.servers[web1].PackageBillingContractId = "1234"
+	m.servers[dupAMS].PackageBilling = "package"
+	m.mu.Unlock()
+
+	tests := []struct {
+		name    string
+		id      string
+		wantID  int
+		want    map[string]interface{}
+		wantErr string
+	}{
+		{
+			name:   "server id",
+			id:     strconv.Itoa(web1),
+			wantID: web1,
+			want: map[string]interface{}{
+				"location":                    "SJC",
+				"location_id":                 1,
+				"image":                       "Ubuntu 22.04 (20221110)",
+				"image_id":                    10,
+				"package_billing":             "usage",
+				"package_billing_contract_id": "1234",
+				"package_billing_opt_in":      "",
+			},
+		},
+		{
+			name:   "hostname",
+			id:     "hostname:web1.example.com",
+			wantID: web1,
+		},
+		{
+			name:   "hostname and location",
+			id:     "hostname:dup.example.com/ams",
+			wantID: dupAMS,
+			want: map[string]interface{}{
+				"location":                    "AMS",
+				"package_billing":             "package",
+				"package_billing_contract_id": "",
+				"package_billing_opt_in":      "yes",
+			},
+		},
+		{
+			name:   "unicode hostname",
+			id:     "hostname:bücher.example.com",
+			wantID: unicode,
+		},
+		{name: "ambiguous hostname", id: "hostname:dup.example.com", wantErr: "Found 2 servers"},
+		{name: "unknown hostname", id: "hostname:nope.example.com", wantErr: "No server found"},
+		{name: "invalid hostname", id: "hostname:bad_host", wantErr: "not a valid hostname"},
+		{name: "not a number", id: "web1", wantErr: "Invalid server import ID"},
+		{name: "zero", id: "0", wantErr: "Invalid server import ID"},
+		{name: "unknown id", id: "999999", wantErr: "Server 999999 doesn't exist"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := resourceServer().TestResourceData()
+			d.SetId(tt.id)
+
+			ctx := context.Background()
+			c := testClient(m)
+
+			result, err := resourceServerImport(ctx, d, c)
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(result) != 1 || result[0].Id() != strconv.Itoa(tt.wantID) {
+				t.Fatalf("expected server %d, got %v", tt.wantID, result)
+			}
+			if diags := resourceServerRead(ctx, result[0], c); diags.HasError() {
+				t.Fatalf("read: %v", diags)
+			}
+			for key, want := range tt.want {
+				if got := result[0].Get(key); got != want {
+					t.Errorf("%s: got %v, want %v", key, got, want)
+				}
+			}
+		})
+	}
+}
+
+// TestServerGeneratedConfig checks that the configuration Terraform generates
+// for an imported server, which sets every optional argument from state, is
+// valid and plans without changes.
+func TestServerGeneratedConfig(t *testing.T) {
+	m := newMockAPI()
+	defer m.Close()
+
+	ctx := context.Background()
+	c := testClient(m)
+
+	id := m.addServer("web1.example.com", 1)
+	m.mu.Lock()
+	m.servers[id].PackageBilling = "usage"
+	m.servers[id].PackageBillingContractId = "1234"
+	m.mu.Unlock()
+
+	r := resourceServer()
+	d := r.TestResourceData()
+	d.SetId(strconv.Itoa(id))
+	if _, err := resourceServerImport(ctx, d, c); err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if diags := resourceServerRead(ctx, d, c); diags.HasError() {
+		t.Fatalf("read: %v", diags)
+	}
+
+	state := d.State()
+	raw := make(map[string]interface{})
+	for key, s := range r.Schema {
+		if !s.Optional && !s.Required {
+			continue
+		}
+		if v, ok := state.Attributes[key]; ok {
+			raw[key] = v
+		}
+	}
+	config := terraform.NewResourceConfigRaw(raw)
+
+	if diags := r.Validate(config); diags.HasError() {
+		t.Fatalf("generated config %v is invalid: %v", raw, diags)
+	}
+
+	diff, err := r.Diff(ctx, state, config, c)
+	if err != nil {
+		t.Fatalf("diff: %v", err)
+	}
+	if !diff.Empty() {
+		t.Errorf("expected no changes for %v, got %v", raw, diff.Attributes)
+	}
+}
+
+// unknownValue is how raw test configurations mark a value that isn't known
+// until apply.
+const unknownValue = "74D93920-ED26-11E3-AC10-0800200C9A66"
+
+func TestValidateBuildArguments(t *testing.T) {
+	base := map[string]interface{}{
+		"hostname": "web1.example.com",
+		"plan":     "VR1x1x25",
+		"location": "SJC",
+		"image":    "Ubuntu 22.04 (20221110)",
+	}
+	with := func(extra map[string]interface{}) map[string]interface{} {
+		raw := make(map[string]interface{})
+		for k, v := range base {
+			raw[k] = v
+		}
+		for k, v := range extra {
+			raw[k] = v
+		}
+		return raw
+	}
+	existing := &terraform.InstanceState{
+		ID: "1234",
+		Attributes: map[string]string{
+			"id":              "1234",
+			"hostname":        "web1.example.com",
+			"plan":            "VR1x1x25",
+			"location":        "SJC",
+			"image":           "Ubuntu 22.04 (20221110)",
+			"package_billing": "usage",
+		},
+	}
+	idn := existing.DeepCopy()
+	idn.Attributes["hostname"] = "xn--bcher-kva.example.com"
+
+	tests := []struct {
+		name    string
+		state   *terraform.InstanceState
+		raw     map[string]interface{}
+		wantErr string
+	}{
+		{
+			name: "new server",
+			raw:  with(map[string]interface{}{"ssh_key": testSSHKey, "package_billing_contract_id": "1234"}),
+		},
+		{
+			name:    "new server without credentials",
+			raw:     with(map[string]interface{}{"package_billing_contract_id": "1234"}),
+			wantErr: "one of `password,ssh_key_id,ssh_key` must be specified",
+		},
+		{
+			name:    "new server without billing",
+			raw:     with(map[string]interface{}{"ssh_key": testSSHKey}),
+			wantErr: "one of `package_billing_contract_id,package_billing_opt_in` must be specified",
+		},
+		{
+			name: "unknown credentials",
+			raw:  with(map[string]interface{}{"ssh_key_id": unknownValue, "package_billing_contract_id": "1234"}),
+		},
+		{
+			name:  "imported server without credentials",
+			state: existing,
+			raw:   with(map[string]interface{}{"package_billing": "usage"}),
+		},
+		{
+			name:  "lowercase location without credentials",
+			state: existing,
+			raw:   with(map[string]interface{}{"package_billing": "usage", "location": "sjc"}),
+		},
+		{
+			name:  "internationalized hostname without credentials",
+			state: idn,
+			raw:   with(map[string]interface{}{"package_billing": "usage", "hostname": "bücher.example.com"}),
+		},
+		{
+			name:    "rebuilding without credentials",
+			state:   existing,
+			raw:     with(map[string]interface{}{"package_billing": "usage", "image": "Debian 12 (20230612)"}),
+			wantErr: "must be specified to build the server",
+		},
+		{
+			name:    "moving without credentials",
+			state:   existing,
+			raw:     with(map[string]interface{}{"package_billing": "usage", "location": "ams"}),
+			wantErr: "must be specified to build the server",
+		},
+		{
+			name:    "replacing without credentials",
+			state:   existing,
+			raw:     with(map[string]interface{}{"package_billing": "usage", "plan": "VR2x2x50"}),
+			wantErr: "must be specified to build the server",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := resourceServer().Diff(context.Background(), tt.state, terraform.NewResourceConfigRaw(tt.raw), nil)
+
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestServerInternationalizedHostname(t *testing.T) {
+	state := &terraform.InstanceState{
+		ID: "1234",
+		Attributes: map[string]string{
+			"id":               "1234",
+			"hostname":         "xn--bcher-kva.example.com",
+			"plan":             "VR1x1x25",
+			"location":         "SJC",
+			"location_id":      "1",
+			"image":            "Ubuntu 22.04 (20221110)",
+			"ssh_key":          testSSHKey,
+			"package_billing":  "usage",
+			"primary_ipv4":     "192.0.2.10",
+			"primary_ipv6":     "2001:db8::10",
+			"ipv4_addresses.#": "0",
+			"ipv6_addresses.#": "0",
+		},
+	}
+
+	tests := []struct {
+		hostname string
+		rebuild  bool
+	}{
+		{hostname: "bücher.example.com"},
+		{hostname: "BÜCHER.example.com"},
+		{hostname: "xn--bcher-kva.example.com"},
+		{hostname: "bücherei.example.com", rebuild: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.hostname, func(t *testing.T) {
+			raw := map[string]interface{}{
+				"hostname": tt.hostname,
+				"plan":     "VR1x1x25",
+				"location": "SJC",
+				"image":    "Ubuntu 22.04 (20221110)",
+				"ssh_key":  testSSHKey,
+			}
+
+			diff, err := resourceServer().Diff(context.Background(), state, terraform.NewResourceConfigRaw(raw), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !tt.rebuild {
+				if !diff.Empty() {
+					t.Errorf("expected no changes, got %v", diff.Attributes)
+				}
+				return
+			}
+			if diff.Empty() || !diff.Attributes["primary_ipv4"].NewComputed {
+				t.Errorf("expected primary_ipv4 to be recomputed, got %v", diff)
+			}
+		})
+	}
+}
+
+// TestServerImportedConfigs checks that an imported server plans without
+// changes whether its location and image are configured by name, by ID or
+// both.
+func TestServerImportedConfigs(t *testing.T) {
+	m := newMockAPI()
+	defer m.Close()
+
+	ctx := context.Background()
+	c := testClient(m)
+
+	id := m.addServer("web1.example.com", 2)
+	m.mu.Lock()
+	m.servers[id].PackageBilling = "usage"
+	m.servers[id].PackageBillingContractId = "1234"
+	m.mu.Unlock()
+
+	r := resourceServer()
+	d := r.TestResourceData()
+	d.SetId(strconv.Itoa(id))
+	if _, err := resourceServerImport(ctx, d, c); err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if diags := resourceServerRead(ctx, d, c); diags.HasError() {
+		t.Fatalf("read: %v", diags)
+	}
+	state := d.State()
+
+	for _, tt := range []map[string]interface{}{
+		{"location": "AMS", "image": "Ubuntu 22.04 (20221110)"},
+		{"location": "ams", "image": "Ubuntu 22.04 (20221110)"},
+		{"location_id": 2, "image_id": 10},
+		{"location": "AMS", "location_id": 2, "image": "Ubuntu 22.04 (20221110)", "image_id": 10},
+	} {
+		raw := map[string]interface{}{
+			"hostname":                    "web1.example.com",
+			"plan":                        "VR1x1x25",
+			"package_billing_contract_id": "1234",
+		}
+		for k, v := range tt {
+			raw[k] = v
+		}
+
+		diff, err := r.Diff(ctx, state, terraform.NewResourceConfigRaw(raw), c)
+		if err != nil {
+			t.Fatalf("%v: %v", tt, err)
+		}
+		if !diff.Empty() {
+			t.Errorf("%v: expected no changes, got %v", tt, diff.Attributes)
+		}
+	}
+}
+
+func TestComputeLocationImageIDs(t *testing.T) {
+	r := resourceServer()
+	state := &terraform.InstanceState{
+		ID: "1234",
+		Attributes: map[string]string{
+			"id":               "1234",
+			"hostname":         "web1.example.com",
+			"plan":             "VR1x1x25",
+			"location":         "SJC",
+			"location_id":      "1",
+			"image":            "Ubuntu 22.04 (20221110)",
+			"image_id":         "10",
+			"ssh_key":          testSSHKey,
+			"package_billing":  "usage",
+			"primary_ipv4":     "192.0.2.10",
+			"primary_ipv6":     "2001:db8::10",
+			"ipv4_addresses.#": "0",
+			"ipv6_addresses.#": "0",
+		},
+	}
+
+	tests := []struct {
+		name     string
+		raw      map[string]interface{}
+		computed []string
+		wantErr  string
+	}{
+		{
+			name:     "location",
+			raw:      map[string]interface{}{"location": "AMS", "image": "Ubuntu 22.04 (20221110)"},
+			computed: []string{"location_id", "primary_ipv4"},
+		},
+		{
+			name:     "image",
+			raw:      map[string]interface{}{"location": "SJC", "image": "Debian 12 (20230612)"},
+			computed: []string{"image_id", "primary_ipv4"},
+		},
+		{
+			name: "both forms",
+			raw: map[string]interface{}{
+				"location": "AMS", "location_id": 2,
+				"image": "Debian 12 (20230612)", "image_id": 11,
+			},
+			computed: []string{"primary_ipv4"},
+		},
+		{
+			name:    "stale location_id",
+			raw:     map[string]interface{}{"location": "AMS", "location_id": 1, "image_id": 10},
+			wantErr: "`location` changed but `location_id` didn't",
+		},
+		{
+			name:    "stale image",
+			raw:     map[string]interface{}{"location_id": 1, "image": "Ubuntu 22.04 (20221110)", "image_id": 11},
+			wantErr: "`image_id` changed but `image` didn't",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw := map[string]interface{}{
+				"hostname": "web1.example.com",
+				"plan":     "VR1x1x25",
+				"ssh_key":  testSSHKey,
+			}
+			for k, v := range tt.raw {
+				raw[k] = v
+			}
+
+			s := state.DeepCopy()
+			s.RawConfig = rawConfig(t, r, raw)
+
+			diff, err := r.Diff(context.Background(), s, terraform.NewResourceConfigRaw(raw), nil)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, key := range tt.computed {
+				if attr := diff.Attributes[key]; attr == nil || !attr.NewComputed {
+					t.Errorf("expected %s to be recomputed, got %v", key, attr)
+				}
+			}
+			for _, key := range []string{"location_id", "image_id"} {
+				if attr := diff.Attributes[key]; attr != nil && attr.NewComputed && !slices.Contains(tt.computed, key) {
+					t.Errorf("expected %s to be kept, got %v", key, attr)
+				}
+			}
+		})
+	}
+}
+
+// rawConfig converts raw to the configuration value Terraform sends with a
+// plan, leaving unset attributes null.
+func rawConfig(t *testing.T, r *schema.Resource, raw map[string]interface{}) cty.Value {
+	t.Helper()
+
+	attrs := make(map[string]cty.Value)
+	for name, ty := range r.CoreConfigSchema().ImpliedType().AttributeTypes() {
+		v, ok := raw[name]
+		if !ok {
+			attrs[name] = cty.NullVal(ty)
+			continue
+		}
+		val, err := gocty.ToCtyValue(v, ty)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		attrs[name] = val
+	}
+	return cty.ObjectVal(attrs)
+}
+
+func TestServerRebuild(t *testing.T) {
+	m := newMockAPI()
+	defer m.Close()
+
+	ctx := context.Background()
+	c := testClient(m)
+	r := resourceServer()
+
+	id := m.addServer("web1.example.com", 1)
+	d := r.TestResourceData()
+	d.SetId(strconv.Itoa(id))
+	d.Set("location", "SJC")
+	d.Set("image", "Ubuntu 22.04 (20221110)")
+	if diags := resourceServerRead(ctx, d, c); diags.HasError() {
+		t.Fatalf("read: %v", diags)
+	}
+
+	raw := map[string]interface{}{
+		"hostname":                    "web1.example.com",
+		"plan":                        "VR1x1x25",
+		"location":                    "AMS",
+		"image":                       "Debian 12 (20230612)",
+		"ssh_key":                     testSSHKey,
+		"package_billing_contract_id": "1234",
+	}
+	diff, err := r.Diff(ctx, d.State(), terraform.NewResourceConfigRaw(raw), c)
+	if err != nil {
+		t.Fatalf("diff: %v", err)
+	}
+
+	state, diags := r.Apply(ctx, d.State(), diff, c)
+	if diags.HasError() {
+		t.Fatalf("apply: %v", diags)
+	}
+
+	for key, want := range map[string]string{
+		"location":    "AMS",
+		"location_id": "2",
+		"image":       "Debian 12 (20230612)",
+		"image_id":    "11",
+	} {
+		if got := state.Attributes[key]; got != want {
+			t.Errorf("%s: got %q, want %q", key, got, want)
+		}
+	}
+}
@@ -2,129 +2,253 @@ package netactuate
 
 import (
 	"context"
+	"fmt"
 	"strconv"
 	"strings"
-	"time"
 
-	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	"github.com/netactuate/gona/gona"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
-func resourceSshKey() *schema.Resource {
-	return &schema.Resource{
-		CreateContext: resourceSshKeyCreate,
-		ReadContext:   resourceSshKeyRead,
-		DeleteContext: resourceSshKeyDelete,
-		UpdateContext: resourceSshKeyUpdate,
-		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
-		},
-		Schema: map[string]*schema.Schema{
-			"name": {
-				Type:     schema.TypeString,
-				Required: true,
-				ForceNew: true,
+var (
+	_ resource.ResourceWithConfigure   = &sshKeyResource{}
+	_ resource.ResourceWithImportState = &sshKeyResource{}
+)
+
+// sshKeyResource is netactuate_sshkey, the first resource served by the
+// framework provider. Its schema and state are unchanged from the SDKv2
+// version, so existing state is read as is.
+type sshKeyResource struct {
+	client apiClient
+}
+
+type sshKeyResourceModel struct {
+	ID          types.String  `tfsdk:"id"`
+	Name        types.String  `tfsdk:"name"`
+	Key         trimmedString `tfsdk:"key"`
+	LastUpdated types.String  `tfsdk:"last_updated"`
+}
+
+func newSshKeyResource() resource.Resource {
+	return &sshKeyResource{}
+}
+
+func (r *sshKeyResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_sshkey"
+}
+
+func (r *sshKeyResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The ID of this resource.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
 			},
-			"key": {
-				Type:     schema.TypeString,
+			"name": schema.StringAttribute{
 				Required: true,
-				ForceNew: true,
-				StateFunc: func(val any) string {
-					return strings.TrimSpace(val.(string))
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"key": schema.StringAttribute{
+				// The SDKv2 resource stored the key trimmed, so only a
+				// different key, not different whitespace, replaces it.
+				CustomType: trimmedStringType{},
+				Required:   true,
+				PlanModifiers: []planmodifier.String{
+					useStateForTrimmedEqual{},
+					stringplanmodifier.RequiresReplace(),
 				},
 			},
-			"last_updated": {
-				Type:     schema.TypeString,
+			"last_updated": schema.StringAttribute{
 				Optional: true,
 				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					useStateOrNullForUnknown{},
+				},
 			},
 		},
 	}
 }
 
-func resourceSshKeyCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*gona.Client)
+// useStateOrNullForUnknown plans the prior value for an unknown
+// last_updated. Unlike UseStateForUnknown it also keeps a null prior value,
+// which is what the SDKv2 resource stored: the provider never sets
+// last_updated, so it can only change through configuration.
+type useStateOrNullForUnknown struct{}
 
-	sshKey, err := c.CreateSSHKey(d.Get("name").(string), d.Get("key").(string))
-	if err != nil {
-		return diag.FromErr(err)
-	}
+func (m useStateOrNullForUnknown) Description(ctx context.Context) string {
+	return "Keeps the prior value, including null, unless the configuration sets one."
+}
 
-	d.SetId(strconv.Itoa(sshKey.ID))
+func (m useStateOrNullForUnknown) MarkdownDescription(ctx context.Context) string {
+	return m.Description(ctx)
+}
 
-	return nil
+func (m useStateOrNullForUnknown) PlanModifyString(ctx context.Context, req planmodifier.StringRequest, resp *planmodifier.StringResponse) {
+	if !req.PlanValue.IsUnknown() || req.ConfigValue.IsUnknown() {
+		return
+	}
+	resp.PlanValue = req.StateValue
 }
 
-func resourceSshKeyRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*gona.Client)
+func (r *sshKeyResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
 
-	id, err := strconv.Atoi(d.Id())
-	if err != nil {
-		return diag.FromErr(err)
+	client, ok := req.ProviderData.(apiClient)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected provider data", fmt.Sprintf("Expected an API client, got %T", req.ProviderData))
+		return
+	}
+	r.client = client
+}
+
+func (r *sshKeyResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan sshKeyResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	sshKey, err := c.GetSSHKey(id)
+	sshKey, err := r.client.CreateSSHKey(plan.Name.ValueString(), plan.Key.ValueString())
 	if err != nil {
-		return diag.FromErr(err)
+		resp.Diagnostics.AddError("Could not create SSH key", err.Error())
+		return
 	}
 
-	var diags diag.Diagnostics
-
-	setValue("name", sshKey.Name, d, &diags)
-	setValue("key", sshKey.Key, d, &diags)
+	plan.ID = types.StringValue(strconv.Itoa(sshKey.ID))
+	if plan.LastUpdated.IsUnknown() {
+		plan.LastUpdated = types.StringNull()
+	}
 
-	return diags
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
 }
 
-func resourceSshKeyDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*gona.Client)
+func (r *sshKeyResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state sshKeyResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
-	id, err := strconv.Atoi(d.Id())
+	id, err := strconv.Atoi(state.ID.ValueString())
 	if err != nil {
-		return diag.FromErr(err)
+		resp.Diagnostics.AddError("Invalid SSH key ID", err.Error())
+		return
 	}
 
-	if id == 0 {
-		return nil
+	sshKey, err := r.client.GetSSHKey(id)
+	if err != nil {
+		resp.Diagnostics.AddError(fmt.Sprintf("Could not read SSH key %d", id), err.Error())
+		return
 	}
 
-	err = c.DeleteSSHKey(id)
-	if err != nil {
-		return diag.FromErr(err)
+	state.Name = types.StringValue(sshKey.Name)
+	// The configured whitespace, e.g. the newline from file(), is kept; see
+	// trimmedString.
+	state.Key = newTrimmedString(sshKey.Key)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// Update only sees changes to last_updated; every other change replaces the
+// key.
+func (r *sshKeyResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan sshKeyResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if plan.LastUpdated.IsUnknown() {
+		plan.LastUpdated = types.StringNull()
 	}
 
-	return nil
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
 }
 
-func resourceSshKeyUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*gona.Client)
+func (r *sshKeyResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state sshKeyResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
-	// Delete the first Key
-	id, err := strconv.Atoi(d.Id())
+	id, err := strconv.Atoi(state.ID.ValueString())
 	if err != nil {
-		return diag.FromErr(err)
+		resp.Diagnostics.AddError("Invalid SSH key ID", err.Error())
+		return
 	}
 
 	if id == 0 {
-		return nil
+		return
+	}
+
+	if err := r.client.DeleteSSHKey(id); err != nil {
+		resp.Diagnostics.AddError(fmt.Sprintf("Could not delete SSH key %d", id), err.Error())
 	}
+}
 
-	err = c.DeleteSSHKey(id)
+func (r *sshKeyResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	id, err := sshKeyImportID(r.client, req.ID)
 	if err != nil {
-		return diag.FromErr(err)
+		resp.Diagnostics.AddError("Could not import SSH key", err.Error())
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), strconv.Itoa(id))...)
+}
+
+// sshKeyImportID accepts a numeric key ID, or fingerprint:<fingerprint> as
+// shown by ssh-keygen -l, e.g. fingerprint:SHA256:nThbg6kXUpJWGl7E1IGOCspRomTxdCARLviKw6E5SY8.
+func sshKeyImportID(c apiClient, importID string) (int, error) {
+	if fingerprint, ok := strings.CutPrefix(importID, "fingerprint:"); ok {
+		keys, err := c.GetSSHKeys()
+		if err != nil {
+			return 0, err
+		}
+
+		var id int
+		var ids []string
+		for _, key := range keys {
+			if key.Fingerprint == fingerprint {
+				id = key.ID
+				ids = append(ids, strconv.Itoa(key.ID))
+			}
+		}
+
+		switch len(ids) {
+		case 0:
+			return 0, fmt.Errorf("No SSH key found with fingerprint %q", fingerprint)
+		case 1:
+			return id, nil
+		}
+		return 0, fmt.Errorf("Found %d SSH keys with fingerprint %q (%s), import by id instead",
+			len(ids), fingerprint, strings.Join(ids, ", "))
 	}
 
-	// Sleep 3 seconds.
-	time.Sleep(3 * time.Second)
+	id, err := strconv.Atoi(importID)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("Invalid SSH key import ID %q, expected a key ID or fingerprint:<fingerprint>", importID)
+	}
 
-	// Create the second key
-	sshKey, err := c.CreateSSHKey(d.Get("name").(string), d.Get("key").(string))
+	key, err := c.GetSSHKey(id)
 	if err != nil {
-		return diag.FromErr(err)
+		return 0, fmt.Errorf("Could not import SSH key %d: %w", id, err)
+	}
+	if key.ID != id {
+		// Like servers, unknown keys may come back empty rather than failing.
+		return 0, fmt.Errorf("SSH key %d doesn't exist", id)
 	}
 
-	d.SetId(strconv.Itoa(sshKey.ID))
-
-	return nil
+	return id, nil
 }
@@ -0,0 +1,286 @@
+package netactuate
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/netactuate/gona/gona"
+)
+
+const testSSHKey = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIGb0 test"
+
+// sshKeySchema returns the netactuate_sshkey schema.
+func sshKeySchema(t *testing.T) schema.Schema {
+	t.Helper()
+
+	var resp resource.SchemaResponse
+	newSshKeyResource().Schema(context.Background(), resource.SchemaRequest{}, &resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("schema: %v", resp.Diagnostics)
+	}
+	return resp.Schema
+}
+
+func TestSshKeyLifecycle(t *testing.T) {
+	m := newMockAPI()
+	defer m.Close()
+
+	ctx := context.Background()
+	r := &sshKeyResource{client: testClient(m)}
+	s := sshKeySchema(t)
+
+	plan := tfsdk.Plan{Schema: s}
+	if diags := plan.Set(ctx, &sshKeyResourceModel{
+		ID:          types.StringUnknown(),
+		Name:        types.StringValue("default_key"),
+		Key:         newTrimmedString(testSSHKey + "\n"),
+		LastUpdated: types.StringUnknown(),
+	}); diags.HasError() {
+		t.Fatalf("plan: %v", diags)
+	}
+
+	created := resource.CreateResponse{State: tfsdk.State{Schema: s}}
+	r.Create(ctx, resource.CreateRequest{Plan: plan}, &created)
+	if created.Diagnostics.HasError() {
+		t.Fatalf("create: %v", created.Diagnostics)
+	}
+
+	read := resource.ReadResponse{State: created.State}
+	r.Read(ctx, resource.ReadRequest{State: created.State}, &read)
+	if read.Diagnostics.HasError() {
+		t.Fatalf("read: %v", read.Diagnostics)
+	}
+
+	var got sshKeyResourceModel
+	if diags := read.State.Get(ctx, &got); diags.HasError() {
+		t.Fatalf("state: %v", diags)
+	}
+	if got.ID.ValueString() == "" {
+		t.Fatal("expected an ID after create")
+	}
+	if got.Name.ValueString() != "default_key" {
+		t.Errorf("name: got %q", got.Name.ValueString())
+	}
+	// Read returns the key as the API stores it; the framework keeps the
+	// configured whitespace because the two are semantically equal.
+	if equal, _ := newTrimmedString(testSSHKey+"\n").StringSemanticEquals(ctx, got.Key); !equal {
+		t.Errorf("key: expected %q to equal the configured key, got %q", got.Key.ValueString(), testSSHKey+"\n")
+	}
+	if !got.LastUpdated.IsNull() {
+		t.Errorf("last_updated: expected null, got %v", got.LastUpdated)
+	}
+
+	deleted := resource.DeleteResponse{}
+	r.Delete(ctx, resource.DeleteRequest{State: read.State}, &deleted)
+	if deleted.Diagnostics.HasError() {
+		t.Fatalf("delete: %v", deleted.Diagnostics)
+	}
+
+	read = resource.ReadResponse{State: created.State}
+	r.Read(ctx, resource.ReadRequest{State: created.State}, &read)
+	if !read.Diagnostics.HasError() {
+		t.Fatal("expected reading a deleted key to fail")
+	}
+}
+
+func TestSshKeyUpdate(t *testing.T) {
+	ctx := context.Background()
+	r := &sshKeyResource{}
+	s := sshKeySchema(t)
+
+	plan := tfsdk.Plan{Schema: s}
+	if diags := plan.Set(ctx, &sshKeyResourceModel{
+		ID:          types.StringValue("1234"),
+		Name:        types.StringValue("default_key"),
+		Key:         newTrimmedString(testSSHKey),
+		LastUpdated: types.StringUnknown(),
+	}); diags.HasError() {
+		t.Fatalf("plan: %v", diags)
+	}
+
+	resp := resource.UpdateResponse{State: tfsdk.State{Schema: s}}
+	r.Update(ctx, resource.UpdateRequest{Plan: plan}, &resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("update: %v", resp.Diagnostics)
+	}
+
+	var got sshKeyResourceModel
+	if diags := resp.State.Get(ctx, &got); diags.HasError() {
+		t.Fatalf("state: %v", diags)
+	}
+	if !got.LastUpdated.IsNull() {
+		t.Errorf("last_updated: expected null, got %v", got.LastUpdated)
+	}
+}
+
+// TestSshKeyPlanWhitespace plans against state written by the SDKv2
+// resource, which stored the key trimmed and never set last_updated.
+func TestSshKeyPlanWhitespace(t *testing.T) {
+	ctx := context.Background()
+	serverFactory, err := ProviderServer(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := serverFactory()
+
+	objectType := sshKeySchema(t).Type().TerraformType(ctx)
+	value := func(key string, id, lastUpdated tftypes.Value) *tfprotov5.DynamicValue {
+		t.Helper()
+		v, err := tfprotov5.NewDynamicValue(objectType, tftypes.NewValue(objectType, map[string]tftypes.Value{
+			"id":           id,
+			"name":         tftypes.NewValue(tftypes.String, "default_key"),
+			"key":          tftypes.NewValue(tftypes.String, key),
+			"last_updated": lastUpdated,
+		}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &v
+	}
+	null := tftypes.NewValue(tftypes.String, nil)
+	prior := value(testSSHKey, tftypes.NewValue(tftypes.String, "1234"), null)
+
+	for _, key := range []string{testSSHKey, testSSHKey + "\n", "  " + testSSHKey + "\n"} {
+		// Terraform proposes the configuration with the prior computed values.
+		resp, err := server.PlanResourceChange(ctx, &tfprotov5.PlanResourceChangeRequest{
+			TypeName:         "netactuate_sshkey",
+			PriorState:       prior,
+			ProposedNewState: value(key, tftypes.NewValue(tftypes.String, "1234"), null),
+			Config:           value(key, null, null),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, d := range resp.Diagnostics {
+			t.Errorf("%q: %s: %s", key, d.Summary, d.Detail)
+		}
+		if len(resp.RequiresReplace) != 0 {
+			t.Errorf("%q: expected no replacement, got %v", key, resp.RequiresReplace)
+		}
+
+		planned, err := resp.PlannedState.Unmarshal(objectType)
+		if err != nil {
+			t.Fatal(err)
+		}
+		priorValue, _ := prior.Unmarshal(objectType)
+		if !planned.Equal(priorValue) {
+			t.Errorf("%q: expected no changes, planned %v", key, planned)
+		}
+	}
+
+	// A different key still replaces it.
+	otherKey := "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIHc1 other"
+	resp, err := server.PlanResourceChange(ctx, &tfprotov5.PlanResourceChangeRequest{
+		TypeName:         "netactuate_sshkey",
+		PriorState:       prior,
+		ProposedNewState: value(otherKey, tftypes.NewValue(tftypes.String, "1234"), null),
+		Config:           value(otherKey, null, null),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.RequiresReplace) != 1 || !resp.RequiresReplace[0].Equal(tftypes.NewAttributePath().WithAttributeName("key")) {
+		t.Errorf("expected key to require replacement, got %v", resp.RequiresReplace)
+	}
+}
+
+func TestSshKeyImport(t *testing.T) {
+	m := newMockAPI()
+	defer m.Close()
+
+	c := testClient(m)
+	key, err := c.CreateSSHKey("default_key", testSSHKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey := "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIHc1 other"
+	for i := 0; i < 2; i++ {
+		if _, err := c.CreateSSHKey("other_key", otherKey); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		id      string
+		wantErr string
+	}{
+		{name: "key id", id: strconv.Itoa(key.ID)},
+		{name: "fingerprint", id: "fingerprint:" + mockFingerprint(testSSHKey)},
+		{name: "duplicate fingerprint", id: "fingerprint:" + mockFingerprint(otherKey), wantErr: "Found 2 SSH keys"},
+		{name: "unknown fingerprint", id: "fingerprint:SHA256:nope", wantErr: "No SSH key found"},
+		{name: "not a number", id: "default_key", wantErr: "Invalid SSH key import ID"},
+		{name: "unknown id", id: "999999", wantErr: "Could not import SSH key 999999"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, err := sshKeyImportID(c, tt.id)
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if id != key.ID {
+				t.Fatalf("expected key %d, got %d", key.ID, id)
+			}
+		})
+	}
+}
+
+func TestSshKeyImportState(t *testing.T) {
+	m := newMockAPI()
+	defer m.Close()
+
+	ctx := context.Background()
+	c := testClient(m)
+	key, err := c.CreateSSHKey("default_key", testSSHKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := &sshKeyResource{client: c}
+	resp := resource.ImportStateResponse{State: tfsdk.State{Schema: sshKeySchema(t)}}
+	resp.State.Raw = tftypes.NewValue(resp.State.Schema.Type().TerraformType(ctx), nil)
+	r.ImportState(ctx, resource.ImportStateRequest{ID: "fingerprint:" + mockFingerprint(testSSHKey)}, &resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("import: %v", resp.Diagnostics)
+	}
+
+	var got sshKeyResourceModel
+	if diags := resp.State.Get(ctx, &got); diags.HasError() {
+		t.Fatalf("state: %v", diags)
+	}
+	if got.ID.ValueString() != strconv.Itoa(key.ID) {
+		t.Errorf("id: got %q, want %d", got.ID.ValueString(), key.ID)
+	}
+}
+
+// emptySSHKeyClient answers every SSH key lookup with an empty key.
+type emptySSHKeyClient struct {
+	apiClient
+}
+
+func (emptySSHKeyClient) GetSSHKey(id int) (gona.SSHKey, error) {
+	return gona.SSHKey{}, nil
+}
+
+func TestSshKeyImportEmptyKey(t *testing.T) {
+	_, err := sshKeyImportID(emptySSHKeyClient{}, "1234")
+	if err == nil || !strings.Contains(err.Error(), "SSH key 1234 doesn't exist") {
+		t.Fatalf("expected a missing key error, got %v", err)
+	}
+}
@@ -0,0 +1,261 @@
+package netactuate
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/netactuate/gona/gona"
+)
+
+// Sweepers clean up after failed acceptance runs against a real account:
+//
+//	NETACTUATE_API_KEY=... go test ./netactuate/ -v -sweep=all
+//
+// NETACTUATE_API_URL points them at another API, such as the mock API.
+
+func TestMain(m *testing.M) {
+	resource.TestMain(m)
+}
+
+func init() {
+	resource.AddTestSweepers("netactuate_bgp_sessions", &resource.Sweeper{
+		Name: "netactuate_bgp_sessions",
+		F:    sweeper(sweepBGPSessions),
+	})
+	resource.AddTestSweepers("netactuate_server", &resource.Sweeper{
+		Name:         "netactuate_server",
+		Dependencies: []string{"netactuate_bgp_sessions"},
+		F:            sweeper(sweepServers),
+	})
+	resource.AddTestSweepers("netactuate_sshkey", &resource.Sweeper{
+		Name:         "netactuate_sshkey",
+		Dependencies: []string{"netactuate_server"},
+		F:            sweeper(sweepSshKeys),
+	})
+}
+
+func sweeper(sweep func(apiClient) error) func(string) error {
+	return func(region string) error {
+		c, err := sharedClient()
+		if err != nil {
+			return err
+		}
+		return sweep(c)
+	}
+}
+
+// testAccPrefix starts the name of everything the acceptance tests create, so
+// that the sweepers can tell leaked test resources from real ones.
+const testAccPrefix = "tf-acc-test"
+
+// sharedClient returns an API client for the sweepers, configured from the
+// same environment variables as the provider.
+func sharedClient() (apiClient, error) {
+	apiKey := os.Getenv("NETACTUATE_API_KEY")
+	if apiKey == "" {
+		return nil, fmt.Errorf("NETACTUATE_API_KEY must be set for sweepers")
+	}
+	if apiUrl := os.Getenv("NETACTUATE_API_URL"); apiUrl != "" {
+		return gona.NewClientCustom(apiKey, apiUrl), nil
+	}
+	return gona.NewClient(apiKey), nil
+}
+
+// sweepServers deletes test servers and cancels their billing, unlinking
+// packages that were already terminated the same way a location change does.
+// A server that can't be swept doesn't stop the others.
+func sweepServers(c apiClient) error {
+	servers, err := c.GetServers()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, server := range servers {
+		if !strings.HasPrefix(server.Name, testAccPrefix) {
+			continue
+		}
+		log.Printf("[INFO] Sweeping server %d (%s)", server.ID, server.Name)
+
+		if err := sweepServer(c, server); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func sweepServer(c apiClient, server gona.Server) error {
+	if server.ServerStatus == "TERMINATED" {
+		if err := c.UnlinkServer(server.ID); err != nil {
+			return fmt.Errorf("unlinking server %d: %w", server.ID, err)
+		}
+	}
+
+	if err := c.DeleteServer(server.ID, true); err != nil {
+		return fmt.Errorf("deleting server %d: %w", server.ID, err)
+	}
+	if _, diags := wait4Status(server.ID, "TERMINATED", c); diags.HasError() {
+		return fmt.Errorf("waiting for server %d: %s", server.ID, diags[0].Summary)
+	}
+	return nil
+}
+
+// sweepSshKeys deletes test SSH keys.
+func sweepSshKeys(c apiClient) error {
+	keys, err := c.GetSSHKeys()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, key := range keys {
+		if !strings.HasPrefix(key.Name, testAccPrefix) {
+			continue
+		}
+		log.Printf("[INFO] Sweeping SSH key %d (%s)", key.ID, key.Name)
+
+		if err := c.DeleteSSHKey(key.ID); err != nil {
+			errs = append(errs, fmt.Errorf("deleting SSH key %d: %w", key.ID, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// sweepBGPSessions reports the BGP sessions of test servers. The API has no
+// call to remove a session, so like netactuate_bgp_sessions itself they go
+// away with their server; this sweeper runs first so they are logged.
+func sweepBGPSessions(c apiClient) error {
+	servers, err := c.GetServers()
+	if err != nil {
+		return err
+	}
+
+	for _, server := range servers {
+		if !strings.HasPrefix(server.Name, testAccPrefix) {
+			continue
+		}
+
+		sessions, err := c.GetBGPSessions(server.ID)
+		if err != nil {
+			return err
+		}
+		for _, session := range sessions {
+			log.Printf("[INFO] BGP session %d (group %d) will be removed with server %d",
+				session.ID, session.GroupID, server.ID)
+		}
+	}
+
+	return nil
+}
+
+func TestSweepers(t *testing.T) {
+	m := newMockAPI()
+	defer m.Close()
+
+	t.Setenv("NETACTUATE_API_KEY", "test")
+	t.Setenv("NETACTUATE_API_URL", m.URL())
+
+	c, err := sharedClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	running := m.addServer(testAccPrefix+"-web1.example.com", 1)
+	terminated := m.addServer(testAccPrefix+"-web2.example.com", 2)
+	keep := m.addServer("web1.example.com", 1)
+
+	if _, err := c.CreateBGPSessions(running, 42, true, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.DeleteServer(terminated, false); err != nil {
+		t.Fatal(err)
+	}
+	if _, diags := wait4Status(terminated, "TERMINATED", c); diags.HasError() {
+		t.Fatal(diags)
+	}
+	if _, err := c.CreateSSHKey(testAccPrefix+"-key", testSSHKey); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.CreateSSHKey("default_key", testSSHKey); err != nil {
+		t.Fatal(err)
+	}
+
+	// Same order as the registered dependencies
+	for _, sweep := range []func(apiClient) error{sweepBGPSessions, sweepServers, sweepSshKeys} {
+		if err := sweep(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	servers, err := c.GetServers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(servers) != 1 || servers[0].ID != keep {
+		t.Errorf("expected only server %d to be left, got %+v", keep, servers)
+	}
+
+	keys, err := c.GetSSHKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 1 || keys[0].Name != "default_key" {
+		t.Errorf("expected only default_key to be left, got %+v", keys)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.bgpSessions) != 0 {
+		t.Errorf("expected the BGP sessions to go with their server, got %d", len(m.bgpSessions))
+	}
+}
+
+// stuckServerClient fails to delete one server.
+type stuckServerClient struct {
+	apiClient
+	stuck int
+}
+
+func (c stuckServerClient) DeleteServer(id int, cancelBilling bool) error {
+	if id == c.stuck {
+		return errors.New("server is locked")
+	}
+	return c.apiClient.DeleteServer(id, cancelBilling)
+}
+
+func TestSweepServersContinuesAfterError(t *testing.T) {
+	m := newMockAPI()
+	defer m.Close()
+
+	stuck := m.addServer(testAccPrefix+"-web1.example.com", 1)
+	m.addServer(testAccPrefix+"-web2.example.com", 2)
+
+	c := stuckServerClient{apiClient: testClient(m), stuck: stuck}
+	err := sweepServers(c)
+	if err == nil || !strings.Contains(err.Error(), fmt.Sprintf("deleting server %d: server is locked", stuck)) {
+		t.Fatalf("expected the stuck server to be reported, got %v", err)
+	}
+
+	servers, err := c.GetServers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(servers) != 1 || servers[0].ID != stuck {
+		t.Errorf("expected only server %d to be left, got %+v", stuck, servers)
+	}
+}
+
+func TestSharedClientRequiresKey(t *testing.T) {
+	t.Setenv("NETACTUATE_API_KEY", "")
+
+	if _, err := sharedClient(); err == nil {
+		t.Fatal("expected an error without NETACTUATE_API_KEY")
+	}
+}
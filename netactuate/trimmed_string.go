@@ -0,0 +1,104 @@
+package netactuate
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+var (
+	_ basetypes.StringTypable                    = trimmedStringType{}
+	_ basetypes.StringValuableWithSemanticEquals = trimmedString{}
+)
+
+// trimmedStringType is a string whose leading and trailing whitespace doesn't
+// matter, like the SSH keys the SDKv2 resources stored through
+// strings.TrimSpace.
+type trimmedStringType struct {
+	basetypes.StringType
+}
+
+func (t trimmedStringType) Equal(o attr.Type) bool {
+	other, ok := o.(trimmedStringType)
+	return ok && t.StringType.Equal(other.StringType)
+}
+
+func (t trimmedStringType) String() string {
+	return "trimmedStringType"
+}
+
+func (t trimmedStringType) ValueType(ctx context.Context) attr.Value {
+	return trimmedString{}
+}
+
+func (t trimmedStringType) ValueFromString(ctx context.Context, in basetypes.StringValue) (basetypes.StringValuable, diag.Diagnostics) {
+	return trimmedString{StringValue: in}, nil
+}
+
+func (t trimmedStringType) ValueFromTerraform(ctx context.Context, in tftypes.Value) (attr.Value, error) {
+	attrValue, err := t.StringType.ValueFromTerraform(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+
+	stringValue, ok := attrValue.(basetypes.StringValue)
+	if !ok {
+		return nil, fmt.Errorf("unexpected value type of %T", attrValue)
+	}
+	return trimmedString{StringValue: stringValue}, nil
+}
+
+type trimmedString struct {
+	basetypes.StringValue
+}
+
+func newTrimmedString(value string) trimmedString {
+	return trimmedString{StringValue: basetypes.NewStringValue(value)}
+}
+
+func (v trimmedString) Type(ctx context.Context) attr.Type {
+	return trimmedStringType{}
+}
+
+func (v trimmedString) Equal(o attr.Value) bool {
+	other, ok := o.(trimmedString)
+	return ok && v.StringValue.Equal(other.StringValue)
+}
+
+// StringSemanticEquals keeps the prior value after create, read and update
+// when the API returns it with different whitespace.
+func (v trimmedString) StringSemanticEquals(ctx context.Context, newValuable basetypes.StringValuable) (bool, diag.Diagnostics) {
+	newValue, diags := newValuable.ToStringValue(ctx)
+	if diags.HasError() {
+		return false, diags
+	}
+	return strings.TrimSpace(v.ValueString()) == strings.TrimSpace(newValue.ValueString()), diags
+}
+
+// useStateForTrimmedEqual plans the prior value when the configuration only
+// differs from it in leading or trailing whitespace. Terraform accepts the
+// prior value as equivalent to the configuration, so the plan is empty.
+type useStateForTrimmedEqual struct{}
+
+func (m useStateForTrimmedEqual) Description(ctx context.Context) string {
+	return "Keeps the prior value when the configuration only differs from it in leading or trailing whitespace."
+}
+
+func (m useStateForTrimmedEqual) MarkdownDescription(ctx context.Context) string {
+	return m.Description(ctx)
+}
+
+func (m useStateForTrimmedEqual) PlanModifyString(ctx context.Context, req planmodifier.StringRequest, resp *planmodifier.StringResponse) {
+	if req.StateValue.IsNull() || req.PlanValue.IsNull() || req.PlanValue.IsUnknown() {
+		return
+	}
+	if strings.TrimSpace(req.StateValue.ValueString()) == strings.TrimSpace(req.PlanValue.ValueString()) {
+		resp.PlanValue = req.StateValue
+	}
+}